@@ -1,15 +1,41 @@
 package enhanced
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"path"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
 
+const (
+	// maxMultiOps is the maximum number of operations sent in one multi.
+	maxMultiOps = 100
+	// maxDeleteAttempts is the maximum number of times a recursive deletion
+	// is started over due to concurrent creation.
+	maxDeleteAttempts = 10
+	// maxCreateAttempts is the maximum number of times parents are re-created
+	// due to concurrent deletion.
+	maxCreateAttempts = 10
+	// protectedPrefix is the name prefix of protected znodes, which is the
+	// same as the one used by zk.Conn.CreateProtectedEphemeralSequential.
+	protectedPrefix = "_c_"
+	// maxProtectedAttempts is the maximum number of attempts of a protected
+	// creation failed due to connection loss.
+	maxProtectedAttempts = 3
+	// protectedRetryInterval is the time to wait before searching for the
+	// znode of a protected creation failed due to connection loss.
+	protectedRetryInterval = time.Second
+)
+
 type basicOperations struct {
 	Conner
-	flags int32
-	acl   []zk.ACL
+	flags       int32
+	acl         []zk.ACL
+	retryPolicy RetryPolicy
+	logger      Logger
 }
 
 func newBasicOperations(conner Conner) basicOperations {
@@ -17,83 +43,389 @@ func newBasicOperations(conner Conner) basicOperations {
 		Conner: conner,
 		flags:  0,
 		acl:    zk.WorldACL(zk.PermAll),
+		logger: NopLogger,
 	}
 }
 
 // SetFlags sets the flags used for all operation.
+// Use WithMode to set the mode of a single creation.
 func (o *basicOperations) SetFlags(flags int32) {
 	o.flags = flags
 }
 
 // SetACL sets the ACL used for all operation.
+// Use WithACL to set the ACL of a single creation.
 func (o *basicOperations) SetACL(acl []zk.ACL) {
 	o.acl = acl
 }
 
-func (o *basicOperations) get(p string) ([]byte, *zk.Stat, error) {
-	return o.Conn().Get(p)
+// SetRetryPolicy sets the RetryPolicy used for all operation.
+// Operations are not retried by default.
+//
+// Operations which are not idempotent are never retried, since the first
+// attempt may have succeeded before the connection was lost. Retrying a
+// creation would fail with zk.ErrNodeExists or create a duplicate sequential
+// znode, and retrying a Set with a version other than -1 would fail with
+// zk.ErrBadVersion. So creations, versioned Sets and committing a Tx are not
+// retried, protected creations are retried safely though, see WithProtection.
+// Deletions are retried, and zk.ErrNoNode of a retried attempt is taken as
+// success.
+func (o *basicOperations) SetRetryPolicy(p RetryPolicy) {
+	o.retryPolicy = p
 }
 
-func (o *basicOperations) exist(p string) (bool, *zk.Stat, error) {
-	return o.Conn().Exists(p)
+// newOpOptions creates opOptions with client-wide settings overridden by opts.
+func (o *basicOperations) newOpOptions(opts []OpOption) *opOptions {
+	var opt = &opOptions{
+		ctx:         context.Background(),
+		retryPolicy: o.retryPolicy,
+		flags:       o.flags,
+		acl:         o.acl,
+	}
+	for _, fn := range opts {
+		fn(opt)
+	}
+	return opt
 }
 
-func (o *basicOperations) getChildren(p string) ([]string, *zk.Stat, error) {
-	return o.Conn().Children(p)
+// newCtxOpOptions is newOpOptions with the context of the operation set to ctx.
+func (o *basicOperations) newCtxOpOptions(ctx context.Context, opts []OpOption) *opOptions {
+	var opt = o.newOpOptions(opts)
+	opt.ctx = ctx
+	return opt
 }
 
-func (o *basicOperations) set(p string, value []byte, version int32) (*zk.Stat, error) {
-	return o.Conn().Set(p, value, version)
+// retry calls fn until it succeeds, fails with an error which is not
+// retryable, the RetryPolicy gives up or the context is done.
+func (o *basicOperations) retry(opt *opOptions, fn func() error) error {
+	var start = time.Now()
+	for retries := 0; ; retries++ {
+		var err = callCtx(opt.ctx, fn)
+		if opt.retryPolicy == nil || !IsRetryableErr(err) {
+			return err
+		}
+		sleep, ok := opt.retryPolicy.AllowRetry(retries, time.Since(start))
+		if !ok {
+			o.logger.Warn("giving up retrying", "retries", retries, "err", err)
+			return err
+		}
+		o.logger.Warn("retrying", "retries", retries, "sleep", sleep, "err", err)
+		select {
+		case <-time.After(sleep):
+		case <-opt.ctx.Done():
+			return opt.ctx.Err()
+		}
+	}
 }
 
-func (o *basicOperations) create(p string) error {
-	_, err := o.Conn().Create(p, nil, o.flags, o.acl)
-	return err
+// callCtx calls fn and returns its error, or the error of ctx if ctx is done first.
+// NOTE: fn keeps running in background after ctx is done, so anything it
+// writes must not be read unless nil error is returned.
+func callCtx(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if ctx.Done() == nil {
+		return fn()
+	}
+	var done = make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-func (o *basicOperations) createValue(p string, value []byte) error {
-	_, err := o.Conn().Create(p, value, o.flags, o.acl)
-	return err
+func (o *basicOperations) get(opt *opOptions, p string) ([]byte, *zk.Stat, error) {
+	var data []byte
+	var stat *zk.Stat
+	var err = o.retry(opt, func() (err error) {
+		data, stat, err = o.Conn().Get(p)
+		return
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, stat, nil
+}
+
+func (o *basicOperations) exist(opt *opOptions, p string) (bool, *zk.Stat, error) {
+	var exist bool
+	var stat *zk.Stat
+	var err = o.retry(opt, func() (err error) {
+		exist, stat, err = o.Conn().Exists(p)
+		return
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return exist, stat, nil
+}
+
+func (o *basicOperations) getChildren(opt *opOptions, p string) ([]string, *zk.Stat, error) {
+	var children []string
+	var stat *zk.Stat
+	var err = o.retry(opt, func() (err error) {
+		children, stat, err = o.Conn().Children(p)
+		return
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return children, stat, nil
+}
+
+func (o *basicOperations) set(opt *opOptions, p string, value []byte, version int32) (*zk.Stat, error) {
+	if version != -1 {
+		opt = opt.withoutRetry()
+	}
+	var stat *zk.Stat
+	var err = o.retry(opt, func() (err error) {
+		stat, err = o.Conn().Set(p, value, version)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+func (o *basicOperations) create(opt *opOptions, p string) (string, error) {
+	return o.createValue(opt, p, nil)
+}
+
+// createValue creates p with value and the options of the operation, the
+// path created is returned.
+func (o *basicOperations) createValue(opt *opOptions, p string, value []byte) (string, error) {
+	var flags, err = opt.createFlags()
+	if err != nil {
+		return "", err
+	}
+	if opt.protected {
+		return o.createProtected(opt, p, value, flags)
+	}
+	return o.doCreate(opt.withoutRetry(), p, value, flags, opt.acl)
 }
 
-func (o *basicOperations) delete(p string, version int32) error {
-	return o.Conn().Delete(p, version)
+func (o *basicOperations) doCreate(opt *opOptions, p string, value []byte, flags int32, acl []zk.ACL) (string, error) {
+	var created string
+	var err = o.retry(opt, func() (err error) {
+		created, err = o.Conn().Create(p, value, flags, acl)
+		return
+	})
+	if err != nil {
+		return "", err
+	}
+	return created, nil
 }
 
-func (o *basicOperations) deleteWithChildren(p string) error {
-	var children, _, err = o.getChildren(p)
+// createProtected creates p with its name prefixed with a GUID, see
+// protectedCreation.
+func (o *basicOperations) createProtected(opt *opOptions, p string, value []byte, flags int32) (string, error) {
+	var guid, err = newGUID()
+	if err != nil {
+		return "", err
+	}
+	var dir = path.Dir(p)
+	var protectedPath = path.Join(dir, ProtectedName(guid, path.Base(p)))
+	var pc = &protectedCreation{
+		ctx:      opt.ctx,
+		prefix:   protectedNamePrefix(guid),
+		interval: protectedRetryInterval,
+		// Results written by fn are read only if callCtx returns nil, since
+		// fn keeps running after opt.ctx is done.
+		create: func() (string, error) {
+			var created string
+			var err = callCtx(opt.ctx, func() (err error) {
+				created, err = o.Conn().Create(protectedPath, value, flags, opt.acl)
+				return
+			})
+			if err != nil {
+				return "", err
+			}
+			return created, nil
+		},
+		children: func() ([]string, error) {
+			var children []string
+			var err = callCtx(opt.ctx, func() (err error) {
+				children, _, err = o.Conn().Children(dir)
+				return
+			})
+			if err != nil {
+				return nil, err
+			}
+			return children, nil
+		},
+	}
+	created, err := pc.run()
 	if err != nil {
+		return "", err
+	}
+	return path.Join(dir, path.Base(created)), nil
+}
+
+// newGUID returns a random hex string used to name protected znodes.
+func newGUID() (string, error) {
+	var b = make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (o *basicOperations) delete(opt *opOptions, p string, version int32) error {
+	return o.retry(opt, retriedDeletion(func() error {
+		return o.Conn().Delete(p, version)
+	}))
+}
+
+// retriedDeletion wraps deleting fn, zk.ErrNoNode is taken as success once fn
+// is retried, since the znode may have been deleted by the previous attempt.
+func retriedDeletion(fn func() error) func() error {
+	var attempted bool
+	return func() error {
+		var err = fn()
+		if err == zk.ErrNoNode && attempted {
+			return nil
+		}
+		attempted = true
 		return err
 	}
-	for _, child := range children {
-		err = o.deleteWithChildren(path.Join(p, child))
-		if err != nil {
+}
+
+func (o *basicOperations) multi(opt *opOptions, reqs ...interface{}) ([]zk.MultiResponse, error) {
+	var responses []zk.MultiResponse
+	var err = o.retry(opt, func() (err error) {
+		responses, err = o.Conn().Multi(reqs...)
+		return
+	})
+	if err != nil && (err == opt.ctx.Err() || len(responses) != len(reqs)) {
+		return nil, err
+	}
+	return responses, err
+}
+
+// deleteWithChildren deletes p and all its descendants.
+// Descendants deleted by others in the meantime are ignored, the deletion is
+// started over if children are created concurrently.
+func (o *basicOperations) deleteWithChildren(opt *opOptions, p string) error {
+	var err error
+	for attempt := 0; attempt < maxDeleteAttempts; attempt++ {
+		var nodes []string
+		if nodes, err = o.collectTree(opt, p, nil); err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			if attempt == 0 {
+				return zk.ErrNoNode
+			}
+			// Deleted by others.
+			return nil
+		}
+		if err = o.deleteNodes(opt, nodes); err != zk.ErrNotEmpty {
 			return err
 		}
 	}
-	return o.delete(p, -1)
+	return err
 }
 
-func (o *basicOperations) createWithParents(p string) error {
-	var err = o.create(p)
+// collectTree appends p and all its descendants to nodes with children
+// placed before their parents.
+func (o *basicOperations) collectTree(opt *opOptions, p string, nodes []string) ([]string, error) {
+	var children, _, err = o.getChildren(opt, p)
 	if err == zk.ErrNoNode {
-		var parent = path.Dir(p)
-		err = o.createWithParents(parent)
-		if err == nil {
-			err = o.create(p)
+		return nodes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if nodes, err = o.collectTree(opt, path.Join(p, child), nodes); err != nil {
+			return nil, err
 		}
 	}
-	return err
+	return append(nodes, p), nil
 }
 
-func (o *basicOperations) createValueWithParents(p string, value []byte) error {
-	var err = o.createValue(p, value)
-	if err == zk.ErrNoNode {
-		var parent = path.Dir(p)
-		err = o.createWithParents(parent)
-		if err == nil {
-			err = o.createValue(p, value)
+// deleteNodes deletes given nodes in order, nodes which do not exist are ignored.
+// Nodes are deleted in batches with multi, a batch is deleted one by one if
+// the multi fails.
+func (o *basicOperations) deleteNodes(opt *opOptions, nodes []string) error {
+	for len(nodes) > 0 {
+		var batch = nodes
+		if len(batch) > maxMultiOps {
+			batch = batch[:maxMultiOps]
+		}
+		nodes = nodes[len(batch):]
+
+		var reqs = make([]interface{}, len(batch))
+		for i, p := range batch {
+			reqs[i] = &zk.DeleteRequest{Path: p, Version: -1}
+		}
+		if _, err := o.multi(opt, reqs...); err == nil {
+			continue
+		}
+		for _, p := range batch {
+			if err := o.delete(opt, p, -1); err != nil && err != zk.ErrNoNode {
+				return err
+			}
 		}
 	}
-	return err
+	return nil
+}
+
+func (o *basicOperations) createWithParents(opt *opOptions, p string) (string, error) {
+	return o.createValueWithParents(opt, p, nil)
+}
+
+// createValueWithParents creates p with value and its missing parents.
+// Parents are always created as persistent znodes with the ACL of the client,
+// and those created by others in the meantime are ignored.
+func (o *basicOperations) createValueWithParents(opt *opOptions, p string, value []byte) (string, error) {
+	var created, err = o.createValue(opt, p, value)
+	for attempt := 0; err == zk.ErrNoNode && attempt < maxCreateAttempts; attempt++ {
+		if err = o.createParents(opt, path.Dir(p)); err != nil {
+			return "", err
+		}
+		// Parents may be deleted by others before p is created.
+		created, err = o.createValue(opt, p, value)
+	}
+	return created, err
+}
+
+// createParents creates p and its missing ancestors as persistent znodes.
+func (o *basicOperations) createParents(opt *opOptions, p string) error {
+	var missing []string
+	for ; p != "/"; p = path.Dir(p) {
+		exist, _, err := o.exist(opt, p)
+		if err != nil {
+			return err
+		}
+		if exist {
+			break
+		}
+		missing = append(missing, p)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var reqs = make([]interface{}, 0, len(missing))
+	for i := len(missing) - 1; i >= 0; i-- {
+		reqs = append(reqs, &zk.CreateRequest{Path: missing[i], Acl: o.acl, Flags: 0})
+	}
+	if _, err := o.multi(opt, reqs...); err == nil {
+		return nil
+	}
+	// Others are creating the same parents, create them one by one.
+	for i := len(missing) - 1; i >= 0; i-- {
+		if _, err := o.doCreate(opt, missing[i], nil, 0, o.acl); err != nil && err != zk.ErrNodeExists {
+			return err
+		}
+	}
+	return nil
 }
@@ -1,6 +1,9 @@
 package enhanced
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -8,9 +11,14 @@ import (
 
 // Client is an enhanced connection.
 type Client struct {
-	conn        *zk.Conn
-	eventUpdate <-chan zk.Event
-	closed      chan struct{}
+	connLock        sync.RWMutex
+	conn            *zk.Conn
+	servers         []string
+	sessionTimeout  time.Duration
+	auths           [][]byte
+	recreateSession int32
+	logger          *syncLogger
+	closed          chan struct{}
 	namespace
 	nsBasicOperations
 	watchOperations
@@ -23,89 +31,216 @@ type Client struct {
 // the session timeout it's possible to reestablish a connection to a different
 // server and keep the same session. This is means any ephemeral nodes and
 // watches are maintained.
+//
+// Connect returns without waiting for the session to be created, use
+// ConnectCtx to wait for it.
 func Connect(servers []string, sessionTimeout time.Duration) (*Client, error) {
-	// TODO:
-	// 1. connect timeout
 	conn, evt, err := zk.Connect(servers, sessionTimeout)
 	if err != nil {
 		return nil, err
 	}
-	return newClient(conn, evt), nil
+	var c = newClient(conn, evt)
+	c.servers = servers
+	c.sessionTimeout = sessionTimeout
+	c.eventWatcher.Start()
+	return c, nil
+}
+
+// ConnectCtx is Connect which blocks until the session is created or ctx is done.
+// The client is closed if ctx is done before the session is created.
+func ConnectCtx(ctx context.Context, servers []string, sessionTimeout time.Duration) (*Client, error) {
+	c, err := Connect(servers, sessionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.BlockUntilConnectedCtx(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
 }
 
 func newClient(conn *zk.Conn, eventUpdate <-chan zk.Event) *Client {
 	var c = &Client{
-		conn:        conn,
-		eventUpdate: eventUpdate,
-		closed:      make(chan struct{}),
+		conn:   conn,
+		logger: newSyncLogger(NopLogger),
+		closed: make(chan struct{}),
 	}
 	c.nsBasicOperations = newNSBasicOperations(c, &c.namespace)
+	c.nsBasicOperations.logger = c.logger
 	c.watchOperations = watchOperations{
 		namespace: &c.namespace,
 		closed:    c.closed,
 		Conner:    c,
 	}
 	c.eventWatcher = newEventWatcher(eventUpdate, c.closed, c)
+	c.eventWatcher.renew = c.renewSession
+	c.eventWatcher.logger = c.logger
 	return c
 }
 
 // Conn returns internal zk.Conn.
+// NOTE: The returned zk.Conn is replaced once the session is re-created,
+// see SetRecreateSessionOnExpire.
 func (c *Client) Conn() *zk.Conn {
+	c.connLock.RLock()
+	defer c.connLock.RUnlock()
 	return c.conn
 }
 
 // Close closes inner connection then stops all watchers.
 func (c *Client) Close() {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
 	c.conn.Close()
 	close(c.closed)
 }
 
 // SetDigestAuth sets auth as digest.
+// The auth is also added to connections of re-created sessions.
 func (c *Client) SetDigestAuth(auth []byte) error {
-	return c.conn.AddAuth("digest", auth)
-}
-
-// Namespace returns namespace used for all operation.
-func (c *Client) Namespace() string {
-	return c.ns()
+	var err = c.Conn().AddAuth("digest", auth)
+	if err == nil {
+		c.connLock.Lock()
+		c.auths = append(c.auths, auth)
+		c.connLock.Unlock()
+	}
+	return err
 }
 
-// SetNamespace sets the namespace used for all operation.
-// NOTE: namespace does not start with /.
-func (c *Client) SetNamespace(ns string) *Client {
-	c.setNS(ns)
+// SetRecreateSessionOnExpire sets whether to establish a new session with a new
+// connection once the session expires, default false.
+// Listeners added by AddSessionListener are called when the new session is created,
+// recipes should re-create their ephemeral nodes and watches there.
+func (c *Client) SetRecreateSessionOnExpire(yes bool) *Client {
+	var v int32
+	if yes {
+		v = 1
+	}
+	atomic.StoreInt32(&c.recreateSession, v)
 	return c
 }
 
-// IsConnected returns true if a session is currently created.
-func (c *Client) IsConnected() bool {
-	return isConnectedState(c.conn.State())
-}
+// renewSession replaces the inner connection with a new one if enabled.
+// Events of the new connection are returned, nil is returned if the
+// connection is not replaced.
+// Auths are added to the new connection before it replaces the old one, so
+// they are applied before operations issued on the new session.
+func (c *Client) renewSession() <-chan zk.Event {
+	if atomic.LoadInt32(&c.recreateSession) == 0 || c.isClosed() {
+		return nil
+	}
+	c.logger.Info("re-creating expired session")
+	conn, evt, err := zk.Connect(c.servers, c.sessionTimeout)
+	if err != nil {
+		c.logger.Error("failed to re-create session", "err", err)
+		return nil
+	}
+	var pending = c.addAuths(conn, evt)
 
-func isConnectedState(s zk.State) bool {
-	switch s {
-	case zk.StateHasSession, zk.StateConnectedReadOnly:
-		return true
-	default:
-		return false
+	c.connLock.Lock()
+	if c.isClosed() {
+		c.connLock.Unlock()
+		conn.Close()
+		return nil
 	}
+	var old = c.conn
+	c.conn = conn
+	c.connLock.Unlock()
+
+	old.Close()
+	return c.prependEvents(pending, evt)
 }
 
-// BlockUntilConnected blocks until session is created.
-// The returning value indicates whether the session is created.
-func (c *Client) BlockUntilConnected(timeout time.Duration) bool {
-	var deadline = time.After(timeout)
-	for {
-		if c.IsConnected() {
-			return true
+// addAuths adds auths of the client to conn, it blocks until the session is
+// created. Events of conn received meanwhile are returned, since zk.Conn
+// drops events once its buffer is full.
+func (c *Client) addAuths(conn *zk.Conn, evt <-chan zk.Event) []zk.Event {
+	c.connLock.RLock()
+	var auths = c.auths
+	c.connLock.RUnlock()
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, auth := range auths {
+			if err := conn.AddAuth("digest", auth); err != nil {
+				c.logger.Error("failed to add auth to re-created session", "err", err)
+			}
 		}
+	}()
+
+	var pending []zk.Event
+	for {
 		select {
-		case evt := <-c.eventUpdate:
-			if isConnectedState(evt.State) {
-				return true
+		case e, ok := <-evt:
+			if !ok {
+				<-done
+				return pending
 			}
-		case <-deadline:
-			return false
+			pending = append(pending, e)
+		case <-done:
+			return pending
 		}
 	}
 }
+
+// prependEvents returns a channel receiving pending followed by events from
+// evt, it's evt itself if nothing is pending.
+func (c *Client) prependEvents(pending []zk.Event, evt <-chan zk.Event) <-chan zk.Event {
+	if len(pending) == 0 {
+		return evt
+	}
+	var out = make(chan zk.Event)
+	go func() {
+		defer close(out)
+		for _, e := range pending {
+			select {
+			case out <- e:
+			case <-c.closed:
+				return
+			}
+		}
+		for e := range evt {
+			select {
+			case out <- e:
+			case <-c.closed:
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (c *Client) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetLogger sets the Logger used for retries and connection state transitions.
+// Messages are discarded by default. It's safe to be called at any time.
+func (c *Client) SetLogger(l Logger) *Client {
+	c.logger.Set(l)
+	return c
+}
+
+// Logger returns the Logger of the client.
+// The returned Logger forwards messages to the one set by the latest SetLogger.
+func (c *Client) Logger() Logger {
+	return c.logger
+}
+
+// Namespace returns namespace used for all operation.
+func (c *Client) Namespace() string {
+	return c.ns()
+}
+
+// SetNamespace sets the namespace used for all operation.
+// NOTE: namespace does not start with /.
+func (c *Client) SetNamespace(ns string) *Client {
+	c.setNS(ns)
+	return c
+}
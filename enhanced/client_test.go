@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
 )
 
 func TestClientWatchNS(t *testing.T) {
@@ -17,3 +18,18 @@ func TestEmptyClientWatchNS(t *testing.T) {
 	assert.Equal(t, "/xxx", c.watchOperations.namespaced("xxx"))
 	assert.Equal(t, "/xxx", c.namespaced("xxx"))
 }
+
+func TestPrependEvents(t *testing.T) {
+	var c = newClient(nil, nil)
+	var evt = make(chan zk.Event, 1)
+	assert.Equal(t, (<-chan zk.Event)(evt), c.prependEvents(nil, evt))
+
+	var out = c.prependEvents([]zk.Event{{State: zk.StateConnecting}, {State: zk.StateConnected}}, evt)
+	evt <- zk.Event{State: zk.StateHasSession}
+	close(evt)
+	var states []zk.State
+	for e := range out {
+		states = append(states, e.State)
+	}
+	assert.Equal(t, []zk.State{zk.StateConnecting, zk.StateConnected, zk.StateHasSession}, states)
+}
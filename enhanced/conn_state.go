@@ -0,0 +1,100 @@
+package enhanced
+
+import (
+	"sync/atomic"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const (
+	// ConnStateLatent indicates no session has been established yet.
+	ConnStateLatent ConnState = iota
+	// ConnStateConnected is sent for the first successful connection to the server.
+	ConnStateConnected
+	// ConnStateSuspended is sent when the connection to the server has been lost,
+	// the session may still be valid.
+	ConnStateSuspended
+	// ConnStateReconnected is sent when a suspended, lost or read-only connection
+	// has been re-established.
+	ConnStateReconnected
+	// ConnStateLost is sent when the session has expired, all ephemeral nodes
+	// and watches of the old session are gone.
+	ConnStateLost
+	// ConnStateReadOnly is sent when the connection has gone into read-only mode.
+	ConnStateReadOnly
+)
+
+// ConnState represents the state of the connection to ZooKeeper.
+type ConnState int32
+
+// String returns the string representation of ConnState.
+// "Unknown" is returned when the state is unknown.
+func (s ConnState) String() string {
+	switch s {
+	case ConnStateLatent:
+		return "Latent"
+	case ConnStateConnected:
+		return "Connected"
+	case ConnStateSuspended:
+		return "Suspended"
+	case ConnStateReconnected:
+		return "Reconnected"
+	case ConnStateLost:
+		return "Lost"
+	case ConnStateReadOnly:
+		return "ReadOnly"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsConnected returns true if the state indicates a usable connection.
+func (s ConnState) IsConnected() bool {
+	switch s {
+	case ConnStateConnected, ConnStateReconnected, ConnStateReadOnly:
+		return true
+	default:
+		return false
+	}
+}
+
+// connStateTracker derives ConnState transitions from zk session states.
+type connStateTracker struct {
+	state ConnState
+}
+
+// Value returns the current state.
+func (t *connStateTracker) Value() ConnState {
+	return ConnState(atomic.LoadInt32((*int32)(&t.state)))
+}
+
+// update feeds a zk session state to the tracker.
+// The new ConnState is returned with true if a transition happened.
+func (t *connStateTracker) update(s zk.State) (ConnState, bool) {
+	var old = t.Value()
+	var next = old
+	switch s {
+	case zk.StateHasSession:
+		switch old {
+		case ConnStateLatent:
+			next = ConnStateConnected
+		case ConnStateSuspended, ConnStateLost, ConnStateReadOnly:
+			next = ConnStateReconnected
+		}
+	case zk.StateConnectedReadOnly:
+		next = ConnStateReadOnly
+	case zk.StateDisconnected:
+		if old.IsConnected() {
+			next = ConnStateSuspended
+		}
+	case zk.StateExpired:
+		if old != ConnStateLatent {
+			next = ConnStateLost
+		}
+	}
+	if next == old {
+		return old, false
+	}
+	atomic.StoreInt32((*int32)(&t.state), int32(next))
+	return next, true
+}
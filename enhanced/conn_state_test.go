@@ -0,0 +1,46 @@
+package enhanced
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestConnStateTransitions(t *testing.T) {
+	var tracker connStateTracker
+	for _, c := range []struct {
+		in      zk.State
+		out     ConnState
+		changed bool
+	}{
+		{zk.StateConnecting, ConnStateLatent, false},
+		{zk.StateDisconnected, ConnStateLatent, false},
+		{zk.StateConnected, ConnStateLatent, false},
+		{zk.StateHasSession, ConnStateConnected, true},
+		{zk.StateHasSession, ConnStateConnected, false},
+		{zk.StateDisconnected, ConnStateSuspended, true},
+		{zk.StateConnecting, ConnStateSuspended, false},
+		{zk.StateHasSession, ConnStateReconnected, true},
+		{zk.StateDisconnected, ConnStateSuspended, true},
+		{zk.StateExpired, ConnStateLost, true},
+		{zk.StateDisconnected, ConnStateLost, false},
+		{zk.StateHasSession, ConnStateReconnected, true},
+		{zk.StateConnectedReadOnly, ConnStateReadOnly, true},
+		{zk.StateHasSession, ConnStateReconnected, true},
+	} {
+		var s, changed = tracker.update(c.in)
+		assert.Equal(t, c.out, s)
+		assert.Equal(t, c.changed, changed)
+		assert.Equal(t, c.out, tracker.Value())
+	}
+}
+
+func TestConnStateIsConnected(t *testing.T) {
+	assert.Equal(t, false, ConnStateLatent.IsConnected())
+	assert.Equal(t, true, ConnStateConnected.IsConnected())
+	assert.Equal(t, false, ConnStateSuspended.IsConnected())
+	assert.Equal(t, true, ConnStateReconnected.IsConnected())
+	assert.Equal(t, false, ConnStateLost.IsConnected())
+	assert.Equal(t, true, ConnStateReadOnly.IsConnected())
+}
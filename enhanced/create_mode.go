@@ -0,0 +1,43 @@
+package enhanced
+
+import (
+	"errors"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// CreateMode represents the mode of a created znode, modes can be combined
+// with |, e.g. ModeEphemeral|ModeSequential.
+//
+// NOTE: There is no mode of container or TTL znodes, nor a WithTTL option.
+// They need ZooKeeper 3.5 and a zk.Conn with CreateContainer and CreateTTL,
+// neither of which is available to this package yet.
+type CreateMode int32
+
+const (
+	// ModePersistent creates a znode which lives until it's deleted.
+	ModePersistent CreateMode = 0
+	// ModeEphemeral creates a znode which is deleted once the session expires.
+	ModeEphemeral CreateMode = 1 << 0
+	// ModeSequential appends a monotonically increasing counter to the name.
+	ModeSequential CreateMode = 1 << 1
+)
+
+// ErrInvalidCreateMode indicates the mode is not a combination of the modes
+// defined.
+var ErrInvalidCreateMode = errors.New("invalid create mode")
+
+// flags returns the flags of zk.Conn.Create for the mode.
+func (m CreateMode) flags() (int32, error) {
+	if m&^(ModeEphemeral|ModeSequential) != 0 {
+		return 0, ErrInvalidCreateMode
+	}
+	var flags int32
+	if m&ModeEphemeral != 0 {
+		flags |= zk.FlagEphemeral
+	}
+	if m&ModeSequential != 0 {
+		flags |= zk.FlagSequence
+	}
+	return flags, nil
+}
@@ -0,0 +1,50 @@
+package enhanced
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestCreateModeFlags(t *testing.T) {
+	for _, c := range []struct {
+		mode  CreateMode
+		flags int32
+		err   error
+	}{
+		{ModePersistent, 0, nil},
+		{ModeEphemeral, zk.FlagEphemeral, nil},
+		{ModeSequential, zk.FlagSequence, nil},
+		{ModeEphemeral | ModeSequential, zk.FlagEphemeral | zk.FlagSequence, nil},
+		{1 << 2, 0, ErrInvalidCreateMode},
+		{ModeEphemeral | 1<<3, 0, ErrInvalidCreateMode},
+	} {
+		flags, err := c.mode.flags()
+		assert.Equal(t, c.flags, flags)
+		assert.Equal(t, c.err, err)
+	}
+}
+
+func TestCreateOptions(t *testing.T) {
+	var c = newClient(nil, nil)
+	c.SetFlags(zk.FlagEphemeral)
+
+	var opt = c.newOpOptions(nil)
+	flags, err := opt.createFlags()
+	assert.Equal(t, int32(zk.FlagEphemeral), flags)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, c.acl, opt.acl)
+
+	var acl = zk.DigestACL(zk.PermRead, "user", "password")
+	opt = c.newOpOptions([]OpOption{WithMode(ModeSequential), WithACL(acl), WithProtection()})
+	flags, err = opt.createFlags()
+	assert.Equal(t, int32(zk.FlagSequence), flags)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, acl, opt.acl)
+	assert.Equal(t, true, opt.protected)
+
+	opt = c.newOpOptions([]OpOption{WithMode(1 << 2)})
+	_, err = opt.createFlags()
+	assert.Equal(t, ErrInvalidCreateMode, err)
+}
@@ -1,6 +1,8 @@
 package enhanced
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -8,32 +10,79 @@ import (
 
 // eventWatcher listens for connection state events.
 type eventWatcher struct {
-	update    <-chan zk.Event
-	listeners *ConnectionStateListeners
-	closed    chan struct{}
+	update       <-chan zk.Event
+	listeners    *ConnectionStateListeners
+	stateChanges *StateChangeListeners
+	sessions     *SessionListeners
+	state        connStateTracker
+	sessionID    int64
+	closed       chan struct{}
+	startOnce    sync.Once
+	// renew is called when the session expires, it returns the events of
+	// the renewed connection or nil if the connection is not renewed.
+	renew  func() <-chan zk.Event
+	logger Logger
 	Conner
 }
 
 func newEventWatcher(update <-chan zk.Event, closed chan struct{}, conner Conner) *eventWatcher {
 	return &eventWatcher{
-		update:    update,
-		listeners: NewConnectionStateListeners(),
-		closed:    closed,
-		Conner:    conner,
+		update:       update,
+		listeners:    NewConnectionStateListeners(),
+		stateChanges: NewStateChangeListeners(),
+		sessions:     NewSessionListeners(),
+		logger:       NopLogger,
+		closed:       closed,
+		Conner:       conner,
 	}
 }
 
 // Start starts to listener for events.
+// Calling Start more than once has no effect.
 func (w *eventWatcher) Start() {
-	go w.loop()
+	w.startOnce.Do(func() {
+		go w.loop()
+	})
 }
 
 func (w *eventWatcher) loop() {
-	select {
-	case e := <-w.update:
-		w.listeners.Broadcast(e)
-	case <-w.closed:
-		return
+	var update = w.update
+	for {
+		select {
+		case e, ok := <-update:
+			if !ok {
+				return
+			}
+			w.listeners.Broadcast(e)
+			if e.Type != zk.EventSession {
+				continue
+			}
+			w.processSessionEvent(e)
+			if e.State == zk.StateExpired && w.renew != nil {
+				if renewed := w.renew(); renewed != nil {
+					update = renewed
+				}
+			}
+		case <-w.closed:
+			return
+		}
+	}
+}
+
+// processSessionEvent broadcasts the ConnState derived from e if it changes,
+// and the new session if it replaces an earlier one.
+func (w *eventWatcher) processSessionEvent(e zk.Event) {
+	if s, changed := w.state.update(e.State); changed {
+		w.logger.Info("connection state changed", "state", s, "server", e.Server)
+		w.stateChanges.Broadcast(s)
+	}
+	if e.State == zk.StateHasSession {
+		var id = w.Conn().SessionID()
+		if w.sessionID != 0 && w.sessionID != id {
+			w.logger.Info("new session created", "sessionID", id, "oldSessionID", w.sessionID)
+			w.sessions.Broadcast(id)
+		}
+		w.sessionID = id
 	}
 }
 
@@ -47,6 +96,34 @@ func (w *eventWatcher) DelListener(listener *ConnectionStateListener) {
 	w.listeners.Del(listener)
 }
 
+// AddStateChangeListener adds a StateChangeListener.
+// Listeners are called with every ConnState transition in order.
+func (w *eventWatcher) AddStateChangeListener(listener *StateChangeListener) {
+	w.stateChanges.Add(listener)
+}
+
+// DelStateChangeListener deletes a StateChangeListener.
+func (w *eventWatcher) DelStateChangeListener(listener *StateChangeListener) {
+	w.stateChanges.Del(listener)
+}
+
+// AddSessionListener adds a SessionListener.
+// Listeners are called when a new session replaces an expired one,
+// by which time all ephemeral nodes and watches of the old session are gone.
+func (w *eventWatcher) AddSessionListener(listener *SessionListener) {
+	w.sessions.Add(listener)
+}
+
+// DelSessionListener deletes a SessionListener.
+func (w *eventWatcher) DelSessionListener(listener *SessionListener) {
+	w.sessions.Del(listener)
+}
+
+// ConnState returns the current ConnState.
+func (w *eventWatcher) ConnState() ConnState {
+	return w.state.Value()
+}
+
 // IsConnected returns true if the client is connected.
 func (w *eventWatcher) IsConnected() bool {
 	return isConnectedState(w.Conn().State())
@@ -55,26 +132,42 @@ func (w *eventWatcher) IsConnected() bool {
 // BlockUntilConnected blocks until session is created.
 // The returning value indicates whether the session is created.
 func (w *eventWatcher) BlockUntilConnected(timeout time.Duration) bool {
-	var deadline = time.After(timeout)
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return w.BlockUntilConnectedCtx(ctx) == nil
+}
+
+// BlockUntilConnectedCtx blocks until session is created or ctx is done.
+// The error of ctx is returned if ctx is done first.
+func (w *eventWatcher) BlockUntilConnectedCtx(ctx context.Context) error {
 	var connected = make(chan struct{}, 1)
-	var tmpListener = NewConnectionStateListener(func(e zk.Event) {
-		if isConnectedState(e.State) {
+	var tmpListener = NewStateChangeListener(func(s ConnState) {
+		if s.IsConnected() {
 			select {
 			case connected <- struct{}{}:
 			default:
 			}
 		}
 	})
-	w.AddListener(tmpListener)
-	defer w.DelListener(tmpListener)
+	w.AddStateChangeListener(tmpListener)
+	defer w.DelStateChangeListener(tmpListener)
 
 	if w.IsConnected() {
-		return true
+		return nil
 	}
 	select {
 	case <-connected:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isConnectedState(s zk.State) bool {
+	switch s {
+	case zk.StateHasSession, zk.StateConnectedReadOnly:
 		return true
-	case <-deadline:
+	default:
 		return false
 	}
 }
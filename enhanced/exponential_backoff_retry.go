@@ -0,0 +1,50 @@
+package enhanced
+
+import (
+	"math/rand"
+	"time"
+)
+
+// maxBackoffShift prevents the sleep time from overflowing.
+const maxBackoffShift = 29
+
+// ExponentialBackoffRetry retries a set number of times with increasing sleep
+// time between retries.
+type ExponentialBackoffRetry struct {
+	baseSleep  time.Duration
+	maxSleep   time.Duration
+	maxRetries int
+}
+
+// NewExponentialBackoffRetry creates ExponentialBackoffRetry.
+// The sleep time before the Nth retry is a random multiple of baseSleep
+// between 1 and 2^(N+1).
+func NewExponentialBackoffRetry(baseSleep time.Duration, maxRetries int) *ExponentialBackoffRetry {
+	return &ExponentialBackoffRetry{
+		baseSleep:  baseSleep,
+		maxRetries: maxRetries,
+	}
+}
+
+// SetMaxSleep sets the upper bound of sleep time between retries.
+// Set to 0 to leave it unbounded, which is the default.
+func (r *ExponentialBackoffRetry) SetMaxSleep(maxSleep time.Duration) *ExponentialBackoffRetry {
+	r.maxSleep = maxSleep
+	return r
+}
+
+// AllowRetry implements RetryPolicy.
+func (r *ExponentialBackoffRetry) AllowRetry(retries int, elapsed time.Duration) (time.Duration, bool) {
+	if retries >= r.maxRetries {
+		return 0, false
+	}
+	var shift = uint(retries + 1)
+	if shift > maxBackoffShift {
+		shift = maxBackoffShift
+	}
+	var sleep = r.baseSleep * time.Duration(1+rand.Int63n(1<<shift))
+	if r.maxSleep > 0 && sleep > r.maxSleep {
+		sleep = r.maxSleep
+	}
+	return sleep, true
+}
@@ -0,0 +1,95 @@
+package enhanced
+
+import "sync/atomic"
+
+// Logger is a leveled logger with key/value fields.
+// keysAndValues are pairs of a key and its value, e.g. "path", "/a", "err", err.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// LogLevel represents the level of a log message.
+type LogLevel int
+
+const (
+	// LogLevelDebug is for messages helping debugging.
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo is for messages of normal events, e.g. state transitions.
+	LogLevelInfo
+	// LogLevelWarn is for messages of recoverable failures, e.g. retries.
+	LogLevelWarn
+	// LogLevelError is for messages of failures which are not handled.
+	LogLevelError
+)
+
+// String returns the string representation of LogLevel.
+// "UNKNOWN" is returned when the level is unknown.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// NopLogger discards all messages.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// syncLogger is a Logger forwarding messages to a Logger which can be
+// replaced while in use.
+type syncLogger struct {
+	v atomic.Value
+}
+
+// loggerHolder keeps the type stored in atomic.Value consistent.
+type loggerHolder struct {
+	Logger
+}
+
+func newSyncLogger(l Logger) *syncLogger {
+	var s = &syncLogger{}
+	s.Set(l)
+	return s
+}
+
+// Set replaces the Logger messages are forwarded to.
+func (s *syncLogger) Set(l Logger) {
+	s.v.Store(loggerHolder{l})
+}
+
+func (s *syncLogger) load() Logger {
+	return s.v.Load().(loggerHolder).Logger
+}
+
+func (s *syncLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.load().Debug(msg, keysAndValues...)
+}
+
+func (s *syncLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.load().Info(msg, keysAndValues...)
+}
+
+func (s *syncLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.load().Warn(msg, keysAndValues...)
+}
+
+func (s *syncLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.load().Error(msg, keysAndValues...)
+}
@@ -0,0 +1,40 @@
+package enhanced
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestClientSetLogger(t *testing.T) {
+	var c = newClient(nil, nil)
+	var logger = c.Logger()
+	var buf bytes.Buffer
+	c.SetLogger(NewStdLogger(log.New(&buf, "", 0), LogLevelDebug))
+	// Loggers taken before SetLogger follow the change.
+	logger.Info("hello")
+	c.nsBasicOperations.logger.Warn("retrying")
+	c.eventWatcher.logger.Error("failed")
+	assert.Equal(t, "[INFO] hello\n[WARN] retrying\n[ERROR] failed\n", buf.String())
+}
+
+func TestClientSetLoggerConcurrently(t *testing.T) {
+	var c = newClient(nil, nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.SetLogger(NewStdLogger(log.New(ioutil.Discard, "", 0), LogLevelDebug))
+		}()
+		go func() {
+			defer wg.Done()
+			c.Logger().Debug("message")
+		}()
+	}
+	wg.Wait()
+}
@@ -1,6 +1,9 @@
 package enhanced
 
-import "path"
+import (
+	"path"
+	"strings"
+)
 
 type namespace string
 
@@ -8,6 +11,18 @@ func (n *namespace) namespaced(p string) string {
 	return path.Join("/", string(*n), p)
 }
 
+// unnamespaced translates a namespaced path back out of the namespace.
+func (n *namespace) unnamespaced(p string) string {
+	var prefix = n.namespaced("")
+	if prefix == "/" {
+		return p
+	}
+	if p == prefix {
+		return "/"
+	}
+	return strings.TrimPrefix(p, prefix)
+}
+
 func (n *namespace) ns() string {
 	return string(*n)
 }
@@ -42,3 +42,12 @@ func TestNSGet(t *testing.T) {
 	ns.setNS("xxx")
 	assert.Equal(t, "xxx", ns.ns())
 }
+
+func TestNSUnnamespaced(t *testing.T) {
+	var ns namespace
+	assert.Equal(t, "/xxx", ns.unnamespaced("/xxx"))
+	ns.setNS("prefix")
+	assert.Equal(t, "/xxx", ns.unnamespaced("/prefix/xxx"))
+	assert.Equal(t, "/xxx/yyy", ns.unnamespaced(ns.namespaced("/xxx/yyy")))
+	assert.Equal(t, "/", ns.unnamespaced("/prefix"))
+}
@@ -1,6 +1,10 @@
 package enhanced
 
-import "github.com/samuel/go-zookeeper/zk"
+import (
+	"context"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
 
 type nsBasicOperations struct {
 	basicOperations
@@ -15,51 +19,112 @@ func newNSBasicOperations(conner Conner, ns *namespace) nsBasicOperations {
 }
 
 // Get fetches value and stat of given znode.
-func (nb *nsBasicOperations) Get(p string) ([]byte, *zk.Stat, error) {
-	return nb.get(nb.namespaced(p))
+func (nb *nsBasicOperations) Get(p string, opts ...OpOption) ([]byte, *zk.Stat, error) {
+	return nb.GetCtx(context.Background(), p, opts...)
+}
+
+// GetCtx is Get with a context.
+func (nb *nsBasicOperations) GetCtx(ctx context.Context, p string, opts ...OpOption) ([]byte, *zk.Stat, error) {
+	return nb.get(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p))
 }
 
 // Exist returns true and stat of given znode if it exists.
-func (nb *nsBasicOperations) Exist(p string) (bool, *zk.Stat, error) {
-	return nb.exist(nb.namespaced(p))
+func (nb *nsBasicOperations) Exist(p string, opts ...OpOption) (bool, *zk.Stat, error) {
+	return nb.ExistCtx(context.Background(), p, opts...)
+}
+
+// ExistCtx is Exist with a context.
+func (nb *nsBasicOperations) ExistCtx(ctx context.Context, p string, opts ...OpOption) (bool, *zk.Stat, error) {
+	return nb.exist(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p))
 }
 
 // GetChildren fetches children of given path.
-func (nb *nsBasicOperations) GetChildren(p string) ([]string, *zk.Stat, error) {
-	return nb.getChildren(nb.namespaced(p))
+func (nb *nsBasicOperations) GetChildren(p string, opts ...OpOption) ([]string, *zk.Stat, error) {
+	return nb.GetChildrenCtx(context.Background(), p, opts...)
+}
+
+// GetChildrenCtx is GetChildren with a context.
+func (nb *nsBasicOperations) GetChildrenCtx(ctx context.Context, p string, opts ...OpOption) ([]string, *zk.Stat, error) {
+	return nb.getChildren(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p))
 }
 
 // Set sets the value on given znode.
-func (nb *nsBasicOperations) Set(p string, value []byte, version int32) (*zk.Stat, error) {
-	return nb.set(nb.namespaced(p), value, version)
+func (nb *nsBasicOperations) Set(p string, value []byte, version int32, opts ...OpOption) (*zk.Stat, error) {
+	return nb.SetCtx(context.Background(), p, value, version, opts...)
+}
+
+// SetCtx is Set with a context.
+func (nb *nsBasicOperations) SetCtx(ctx context.Context, p string, value []byte, version int32, opts ...OpOption) (*zk.Stat, error) {
+	return nb.set(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p), value, version)
+}
+
+// Create creates given znode with value set to nil, the path created is
+// returned, which differs from p for sequential or protected znodes.
+func (nb *nsBasicOperations) Create(p string, opts ...OpOption) (string, error) {
+	return nb.CreateCtx(context.Background(), p, opts...)
+}
+
+// CreateCtx is Create with a context.
+func (nb *nsBasicOperations) CreateCtx(ctx context.Context, p string, opts ...OpOption) (string, error) {
+	return nb.unnamespacedPath(nb.create(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p)))
 }
 
-// Create creates given znode with value set to nil.
-func (nb *nsBasicOperations) Create(p string) error {
-	return nb.create(nb.namespaced(p))
+// CreateValue creates given znode with value, the path created is returned.
+func (nb *nsBasicOperations) CreateValue(p string, value []byte, opts ...OpOption) (string, error) {
+	return nb.CreateValueCtx(context.Background(), p, value, opts...)
 }
 
-// CreateValue creates given znode with value.
-func (nb *nsBasicOperations) CreateValue(p string, value []byte) error {
-	return nb.createValue(nb.namespaced(p), value)
+// CreateValueCtx is CreateValue with a context.
+func (nb *nsBasicOperations) CreateValueCtx(ctx context.Context, p string, value []byte, opts ...OpOption) (string, error) {
+	return nb.unnamespacedPath(nb.createValue(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p), value))
 }
 
 // Delete deletes given znode.
-func (nb *nsBasicOperations) Delete(p string, version int32) error {
-	return nb.delete(nb.namespaced(p), version)
+func (nb *nsBasicOperations) Delete(p string, version int32, opts ...OpOption) error {
+	return nb.DeleteCtx(context.Background(), p, version, opts...)
+}
+
+// DeleteCtx is Delete with a context.
+func (nb *nsBasicOperations) DeleteCtx(ctx context.Context, p string, version int32, opts ...OpOption) error {
+	return nb.delete(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p), version)
 }
 
 // DeleteWithChildren deletes given znode with its children if any.
-func (nb *nsBasicOperations) DeleteWithChildren(p string) error {
-	return nb.deleteWithChildren(nb.namespaced(p))
+func (nb *nsBasicOperations) DeleteWithChildren(p string, opts ...OpOption) error {
+	return nb.DeleteWithChildrenCtx(context.Background(), p, opts...)
+}
+
+// DeleteWithChildrenCtx is DeleteWithChildren with a context.
+func (nb *nsBasicOperations) DeleteWithChildrenCtx(ctx context.Context, p string, opts ...OpOption) error {
+	return nb.deleteWithChildren(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p))
+}
+
+// CreateWithParents create path with its parents created if missing, the path
+// created is returned.
+func (nb *nsBasicOperations) CreateWithParents(p string, opts ...OpOption) (string, error) {
+	return nb.CreateWithParentsCtx(context.Background(), p, opts...)
+}
+
+// CreateWithParentsCtx is CreateWithParents with a context.
+func (nb *nsBasicOperations) CreateWithParentsCtx(ctx context.Context, p string, opts ...OpOption) (string, error) {
+	return nb.unnamespacedPath(nb.createWithParents(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p)))
 }
 
-// CreateWithParents create path with its parents created if missing.
-func (nb *nsBasicOperations) CreateWithParents(p string) error {
-	return nb.createWithParents(nb.namespaced(p))
+// CreateValueWithParents create path with value and its parents created if
+// missing, the path created is returned.
+func (nb *nsBasicOperations) CreateValueWithParents(p string, value []byte, opts ...OpOption) (string, error) {
+	return nb.CreateValueWithParentsCtx(context.Background(), p, value, opts...)
 }
 
-// CreateValueWithParents create path with value and its parents created if missing.
-func (nb *nsBasicOperations) CreateValueWithParents(p string, value []byte) error {
-	return nb.createValueWithParents(p, value)
+// CreateValueWithParentsCtx is CreateValueWithParents with a context.
+func (nb *nsBasicOperations) CreateValueWithParentsCtx(ctx context.Context, p string, value []byte, opts ...OpOption) (string, error) {
+	return nb.unnamespacedPath(nb.createValueWithParents(nb.newCtxOpOptions(ctx, opts), nb.namespaced(p), value))
+}
+
+// unnamespacedPath translates the path created back out of the namespace.
+func (nb *nsBasicOperations) unnamespacedPath(created string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return nb.unnamespaced(created), nil
 }
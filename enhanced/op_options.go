@@ -0,0 +1,73 @@
+package enhanced
+
+import (
+	"context"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// OpOption configures a single operation.
+type OpOption func(*opOptions)
+
+// opOptions contains the settings of a single operation.
+type opOptions struct {
+	ctx         context.Context
+	retryPolicy RetryPolicy
+	// flags is used by creation unless mode is set.
+	flags     int32
+	mode      CreateMode
+	hasMode   bool
+	acl       []zk.ACL
+	protected bool
+}
+
+// withoutRetry returns a copy of o which is not retried.
+func (o *opOptions) withoutRetry() *opOptions {
+	var c = *o
+	c.retryPolicy = nil
+	return &c
+}
+
+// createFlags returns the flags of creation.
+func (o *opOptions) createFlags() (int32, error) {
+	if !o.hasMode {
+		return o.flags, nil
+	}
+	return o.mode.flags()
+}
+
+// WithRetryPolicy overrides the RetryPolicy of the client for the operation.
+// Set to nil to disable retrying.
+func WithRetryPolicy(p RetryPolicy) OpOption {
+	return func(o *opOptions) {
+		o.retryPolicy = p
+	}
+}
+
+// WithMode overrides the flags of the client for the creation.
+func WithMode(m CreateMode) OpOption {
+	return func(o *opOptions) {
+		o.mode = m
+		o.hasMode = true
+	}
+}
+
+// WithACL overrides the ACL of the client for the creation.
+// Parents created along are not affected.
+func WithACL(acl []zk.ACL) OpOption {
+	return func(o *opOptions) {
+		o.acl = acl
+	}
+}
+
+// WithProtection prefixes the name of the created znode with a GUID, so it's
+// found instead of created again if the creation is retried after a
+// connection loss. It's useful for sequential znodes, whose names are not
+// known until created.
+// A protected creation is attempted at most 3 times on connection loss,
+// regardless of the RetryPolicy.
+func WithProtection() OpOption {
+	return func(o *opOptions) {
+		o.protected = true
+	}
+}
@@ -0,0 +1,368 @@
+package enhanced
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// rearmInterval is the time to wait before re-registering a watch which failed.
+const rearmInterval = time.Second
+
+// WatchMode represents the mode of a PersistentWatch.
+type WatchMode int
+
+const (
+	// WatchModePersistent watches creation, deletion, data and children
+	// changes of the path.
+	WatchModePersistent WatchMode = iota
+	// WatchModePersistentRecursive watches creation, deletion and data
+	// changes of the path and all its descendants.
+	WatchModePersistentRecursive
+)
+
+// PersistentWatch is a watch which stays registered until it's closed.
+//
+// NOTE: The underlying client does not support persistent watches of
+// ZooKeeper 3.6, one-shot watches are re-registered after every event
+// instead. Changes happened between an event and the re-registration are
+// coalesced, e.g. a znode deleted and re-created in the meantime is reported
+// as changed. Watches are registered again once a new session replaces an
+// expired one, changes happened in between are not delivered.
+//
+// Events are delivered one at a time in the order they are received from
+// ZooKeeper, events of different znodes may be received in an order other
+// than the changes were made.
+type PersistentWatch struct {
+	client          *Client
+	path            string
+	recursive       bool
+	processEvent    func(zk.Event)
+	events          chan zk.Event
+	ctx             context.Context
+	cancel          context.CancelFunc
+	sessionListener *SessionListener
+	closeOnce       sync.Once
+	done            chan struct{}
+
+	queueLock sync.Mutex
+	queue     []zk.Event
+	queued    chan struct{}
+
+	lock sync.Mutex
+	gen  *watchGeneration
+}
+
+// watchGeneration contains the watches registered within a session.
+type watchGeneration struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// children contains known children of nodes whose children are watched.
+	children map[string]map[string]struct{}
+}
+
+// WatchPersistent watches given path until the returned PersistentWatch is closed.
+// processEvent is called from a single goroutine, so a slow processEvent delays
+// further events without losing them. Paths of the events are relative to the
+// namespace.
+func (c *Client) WatchPersistent(p string, mode WatchMode, processEvent func(zk.Event)) *PersistentWatch {
+	var pw = newPersistentWatch(c, p, mode)
+	pw.processEvent = processEvent
+	pw.start()
+	return pw
+}
+
+// WatchPersistentChan is WatchPersistent with events delivered over
+// PersistentWatch.Events, which is closed once the PersistentWatch is closed.
+func (c *Client) WatchPersistentChan(p string, mode WatchMode, bufferSize int) *PersistentWatch {
+	var pw = newPersistentWatch(c, p, mode)
+	pw.events = make(chan zk.Event, bufferSize)
+	pw.processEvent = pw.sendEvent
+	pw.start()
+	return pw
+}
+
+func newPersistentWatch(c *Client, p string, mode WatchMode) *PersistentWatch {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var pw = &PersistentWatch{
+		client:    c,
+		path:      path.Join("/", p),
+		recursive: mode == WatchModePersistentRecursive,
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		queued:    make(chan struct{}, 1),
+	}
+	pw.sessionListener = NewSessionListener(func(int64) {
+		pw.renew()
+	})
+	return pw
+}
+
+// Events returns the channel of events.
+// nil is returned if the PersistentWatch is created by WatchPersistent.
+func (pw *PersistentWatch) Events() <-chan zk.Event {
+	return pw.events
+}
+
+// Close stops watching.
+// No event is delivered once Close returns, except the one being processed by
+// processEvent at the moment. Events is closed before Close returns.
+func (pw *PersistentWatch) Close() {
+	pw.closeOnce.Do(func() {
+		pw.client.DelSessionListener(pw.sessionListener)
+		pw.cancel()
+		if pw.events != nil {
+			<-pw.done
+		}
+	})
+}
+
+func (pw *PersistentWatch) start() {
+	go pw.dispatch()
+	pw.client.AddSessionListener(pw.sessionListener)
+	pw.renew()
+}
+
+// dispatch calls processEvent with queued events in order until closed.
+func (pw *PersistentWatch) dispatch() {
+	defer close(pw.done)
+	if pw.events != nil {
+		defer close(pw.events)
+	}
+	for {
+		select {
+		case <-pw.queued:
+		case <-pw.ctx.Done():
+			return
+		}
+		for {
+			var evt, ok = pw.dequeue()
+			if !ok {
+				break
+			}
+			if pw.ctx.Err() != nil {
+				return
+			}
+			pw.processEvent(evt)
+		}
+	}
+}
+
+func (pw *PersistentWatch) dequeue() (zk.Event, bool) {
+	pw.queueLock.Lock()
+	defer pw.queueLock.Unlock()
+	if len(pw.queue) == 0 {
+		return zk.Event{}, false
+	}
+	var evt = pw.queue[0]
+	pw.queue[0] = zk.Event{}
+	pw.queue = pw.queue[1:]
+	return evt, true
+}
+
+// renew drops all registered watches then registers them again.
+func (pw *PersistentWatch) renew() {
+	pw.lock.Lock()
+	if pw.gen != nil {
+		pw.gen.cancel()
+	}
+	var ctx, cancel = context.WithCancel(pw.ctx)
+	var gen = &watchGeneration{
+		ctx:      ctx,
+		cancel:   cancel,
+		children: make(map[string]map[string]struct{}),
+	}
+	pw.gen = gen
+	pw.lock.Unlock()
+
+	pw.watchRoot(gen)
+}
+
+func (pw *PersistentWatch) watchRoot(gen *watchGeneration) {
+	pw.client.WatchExistCtx(gen.ctx, pw.path, func(result ExistResult) {
+		if pw.retryOnErr(gen, result.Err, pw.watchRoot) {
+			return
+		}
+		if result.Exist {
+			pw.watchChildren(gen, pw.path, false)
+		}
+	}, func(evt zk.Event) {
+		if evt.Type == zk.EventNotWatching {
+			// Session expired, watches are renewed by sessionListener.
+			return
+		}
+		pw.deliver(gen, evt)
+		pw.watchRoot(gen)
+	})
+}
+
+// watchNode watches a descendant of the root recursively.
+// report indicates whether the creation of its children should be delivered.
+func (pw *PersistentWatch) watchNode(gen *watchGeneration, p string, report bool) {
+	pw.client.WatchDataCtx(gen.ctx, p, func(result DataResult) {
+		if result.Err == zk.ErrNoNode {
+			pw.forget(gen, p)
+			return
+		}
+		if pw.retryOnErr(gen, result.Err, func(gen *watchGeneration) {
+			pw.watchNode(gen, p, report)
+		}) {
+			return
+		}
+		pw.watchChildren(gen, p, report)
+	}, func(evt zk.Event) {
+		if evt.Type == zk.EventNotWatching {
+			return
+		}
+		pw.deliver(gen, evt)
+		if evt.Type == zk.EventNodeDeleted {
+			pw.forget(gen, p)
+			return
+		}
+		pw.watchNode(gen, p, true)
+	})
+}
+
+// watchChildren watches children of p unless they are watched already.
+func (pw *PersistentWatch) watchChildren(gen *watchGeneration, p string, report bool) {
+	pw.lock.Lock()
+	if _, watched := gen.children[p]; watched {
+		pw.lock.Unlock()
+		return
+	}
+	gen.children[p] = make(map[string]struct{})
+	pw.lock.Unlock()
+
+	pw.listChildren(gen, p, report)
+}
+
+func (pw *PersistentWatch) listChildren(gen *watchGeneration, p string, report bool) {
+	pw.client.WatchChildrenCtx(gen.ctx, p, func(result ChildrenResult) {
+		if result.Err == zk.ErrNoNode {
+			pw.lock.Lock()
+			delete(gen.children, p)
+			pw.lock.Unlock()
+			// The node may be re-created before its children watch is dropped.
+			if exist, _, err := pw.client.ExistCtx(gen.ctx, p); err == nil && exist {
+				pw.watchChildren(gen, p, true)
+			}
+			return
+		}
+		if pw.retryOnErr(gen, result.Err, func(gen *watchGeneration) {
+			pw.listChildren(gen, p, report)
+		}) {
+			return
+		}
+		if !pw.recursive {
+			return
+		}
+		for _, child := range pw.addChildren(gen, p, result.Children) {
+			var childPath = path.Join(p, child)
+			if report {
+				pw.deliverCreated(gen, childPath)
+			}
+			pw.watchNode(gen, childPath, report)
+		}
+	}, func(evt zk.Event) {
+		if evt.Type == zk.EventNotWatching {
+			return
+		}
+		if !pw.recursive && evt.Type == zk.EventNodeChildrenChanged {
+			pw.deliver(gen, evt)
+		}
+		pw.listChildren(gen, p, true)
+	})
+}
+
+// addChildren adds children to the known children of p.
+// Children which are not known before are returned.
+func (pw *PersistentWatch) addChildren(gen *watchGeneration, p string, children []string) []string {
+	pw.lock.Lock()
+	defer pw.lock.Unlock()
+	var known, ok = gen.children[p]
+	if !ok {
+		return nil
+	}
+	var added []string
+	for _, child := range children {
+		if _, exists := known[child]; !exists {
+			known[child] = struct{}{}
+			added = append(added, child)
+		}
+	}
+	return added
+}
+
+// forget removes p from the known children of its parent, p is watched
+// again if it's re-created in the meantime.
+func (pw *PersistentWatch) forget(gen *watchGeneration, p string) {
+	var parent, child = path.Dir(p), path.Base(p)
+	pw.lock.Lock()
+	if known, ok := gen.children[parent]; ok {
+		delete(known, child)
+	}
+	pw.lock.Unlock()
+
+	if exist, _, err := pw.client.ExistCtx(gen.ctx, p); err == nil && exist {
+		if len(pw.addChildren(gen, parent, []string{child})) > 0 {
+			pw.deliverCreated(gen, p)
+			pw.watchNode(gen, p, true)
+		}
+	}
+}
+
+// retryOnErr re-registers the watch with rearm later if err is not nil.
+// The returning value indicates whether err is not nil.
+func (pw *PersistentWatch) retryOnErr(gen *watchGeneration, err error, rearm func(*watchGeneration)) bool {
+	if err == nil {
+		return false
+	}
+	if gen.ctx.Err() == nil {
+		pw.client.Logger().Warn("re-registering failed watch", "path", pw.path, "err", err)
+		go func() {
+			select {
+			case <-time.After(rearmInterval):
+				rearm(gen)
+			case <-gen.ctx.Done():
+			}
+		}()
+	}
+	return true
+}
+
+// deliver queues evt for processEvent unless the generation is dropped.
+// The path of evt is translated out of the namespace.
+func (pw *PersistentWatch) deliver(gen *watchGeneration, evt zk.Event) {
+	evt.Path = pw.client.unnamespaced(evt.Path)
+	pw.queueLock.Lock()
+	if gen.ctx.Err() != nil {
+		pw.queueLock.Unlock()
+		return
+	}
+	pw.queue = append(pw.queue, evt)
+	pw.queueLock.Unlock()
+
+	select {
+	case pw.queued <- struct{}{}:
+	default:
+	}
+}
+
+// deliverCreated delivers the creation of p found by listing children.
+func (pw *PersistentWatch) deliverCreated(gen *watchGeneration, p string) {
+	pw.deliver(gen, zk.Event{
+		Type: zk.EventNodeCreated,
+		Path: pw.client.namespaced(p),
+	})
+}
+
+func (pw *PersistentWatch) sendEvent(evt zk.Event) {
+	select {
+	case pw.events <- evt:
+	case <-pw.ctx.Done():
+	}
+}
@@ -0,0 +1,33 @@
+package enhanced
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestPersistentWatchDispatch(t *testing.T) {
+	var pw = newPersistentWatch(newClient(nil, nil).SetNamespace("ns"), "/a", WatchModePersistent)
+	pw.events = make(chan zk.Event, 10)
+	pw.processEvent = pw.sendEvent
+	go pw.dispatch()
+
+	var ctx, cancel = context.WithCancel(pw.ctx)
+	var gen = &watchGeneration{ctx: ctx, cancel: cancel}
+	for _, p := range []string{"/ns/a", "/ns/a/b", "/ns/a/c"} {
+		pw.deliver(gen, zk.Event{Type: zk.EventNodeCreated, Path: p})
+	}
+	for _, p := range []string{"/a", "/a/b", "/a/c"} {
+		assert.Equal(t, p, (<-pw.Events()).Path)
+	}
+
+	// Events of dropped generations are discarded.
+	gen.cancel()
+	pw.deliver(gen, zk.Event{Type: zk.EventNodeCreated, Path: "/ns/a/d"})
+	pw.cancel()
+	<-pw.done
+	var _, ok = <-pw.Events()
+	assert.Equal(t, false, ok)
+}
@@ -0,0 +1,155 @@
+package enhanced_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func nextWatchEvent(t *testing.T, pw *enhanced.PersistentWatch) zk.Event {
+	select {
+	case evt, ok := <-pw.Events():
+		if !ok {
+			t.Fatal("Events closed")
+		}
+		return evt
+	case <-time.After(time.Second * 5):
+		t.Fatal("Waiting for event timed out")
+	}
+	return zk.Event{}
+}
+
+func expectWatchEvent(t *testing.T, pw *enhanced.PersistentWatch, tp zk.EventType, p string) {
+	var evt = nextWatchEvent(t, pw)
+	assert.Equal(t, tp, evt.Type, "event of %s", evt.Path)
+	assert.Equal(t, p, evt.Path)
+}
+
+func expectNoWatchEvent(t *testing.T, pw *enhanced.PersistentWatch) {
+	select {
+	case evt := <-pw.Events():
+		t.Fatalf("Unexpected event: %v", evt)
+	case <-time.After(time.Millisecond * 500):
+	}
+}
+
+func TestWatchPersistent(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		var pw = client.WatchPersistentChan("/root", enhanced.WatchModePersistent, 10)
+		defer pw.Close()
+		time.Sleep(time.Millisecond * 200)
+
+		_, err := client.Create("/root")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeCreated, "/root")
+
+		_, err = client.Set("/root", []byte("1"), -1)
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeDataChanged, "/root")
+
+		_, err = client.Create("/root/child")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeChildrenChanged, "/root")
+
+		// Descendants are not watched.
+		_, err = client.Set("/root/child", []byte("1"), -1)
+		assert.NoError(t, err)
+		expectNoWatchEvent(t, pw)
+
+		assert.NoError(t, client.Delete("/root/child", -1))
+		expectWatchEvent(t, pw, zk.EventNodeChildrenChanged, "/root")
+
+		assert.NoError(t, client.Delete("/root", -1))
+		expectWatchEvent(t, pw, zk.EventNodeDeleted, "/root")
+		expectNoWatchEvent(t, pw)
+	})
+}
+
+func TestWatchPersistentRecursive(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		_, err := client.Create("/root")
+		assert.NoError(t, err)
+		var pw = client.WatchPersistentChan("/root", enhanced.WatchModePersistentRecursive, 10)
+		defer pw.Close()
+		time.Sleep(time.Millisecond * 200)
+
+		_, err = client.Create("/root/a")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeCreated, "/root/a")
+
+		_, err = client.Create("/root/a/b")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeCreated, "/root/a/b")
+
+		_, err = client.Set("/root/a/b", []byte("1"), -1)
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeDataChanged, "/root/a/b")
+
+		assert.NoError(t, client.Delete("/root/a/b", -1))
+		expectWatchEvent(t, pw, zk.EventNodeDeleted, "/root/a/b")
+
+		// Deleted nodes are watched again once re-created.
+		_, err = client.Create("/root/a/b")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeCreated, "/root/a/b")
+		expectNoWatchEvent(t, pw)
+
+		pw.Close()
+		_, ok := <-pw.Events()
+		assert.False(t, ok)
+	})
+}
+
+func TestWatchPersistentNamespaced(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClient().SetNamespace("ns")
+		_, err := client.CreateWithParents("/root")
+		assert.NoError(t, err)
+		var pw = client.WatchPersistentChan("/root", enhanced.WatchModePersistentRecursive, 10)
+		defer pw.Close()
+		time.Sleep(time.Millisecond * 200)
+
+		_, err = client.Create("/root/a")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeCreated, "/root/a")
+	})
+}
+
+func TestWatchPersistentSessionExpired(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client, proxy = env.NewProxiedClientTimeout(time.Second * 2)
+		client.SetRecreateSessionOnExpire(true)
+		_, err := client.Create("/root")
+		assert.NoError(t, err)
+		var pw = client.WatchPersistentChan("/root", enhanced.WatchModePersistentRecursive, 10)
+		defer pw.Close()
+		time.Sleep(time.Millisecond * 200)
+
+		var renewed = make(chan int64, 1)
+		client.AddSessionListener(enhanced.NewSessionListener(func(id int64) {
+			renewed <- id
+		}))
+		proxy.ExpireSession()
+		select {
+		case <-renewed:
+		case <-time.After(time.Second * 10):
+			t.Fatal("Waiting for new session timed out")
+		}
+		time.Sleep(time.Millisecond * 500)
+
+		// Watches are registered again within the new session.
+		_, err = env.Client().Create("/root/a")
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeCreated, "/root/a")
+		_, err = env.Client().Set("/root/a", []byte("1"), -1)
+		assert.NoError(t, err)
+		expectWatchEvent(t, pw, zk.EventNodeDataChanged, "/root/a")
+		expectNoWatchEvent(t, pw)
+	})
+}
@@ -0,0 +1,103 @@
+package enhanced
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+)
+
+// ProtectedName returns the name of a znode protected with guid, in the same
+// scheme as the one used by WithProtection.
+func ProtectedName(guid string, name string) string {
+	return protectedNamePrefix(guid) + name
+}
+
+func protectedNamePrefix(guid string) string {
+	return protectedPrefix + guid + "-"
+}
+
+// FindProtected returns the path of the znode under dir protected with guid
+// and owned by the current session, "" is returned if not found.
+func (c *Client) FindProtected(dir string, guid string) (string, error) {
+	var children, _, err = c.GetChildren(dir)
+	if err != nil {
+		return "", err
+	}
+	var prefix = protectedNamePrefix(guid)
+	var session = c.Conn().SessionID()
+	for _, child := range children {
+		if !strings.HasPrefix(child, prefix) {
+			continue
+		}
+		var p = path.Join(dir, child)
+		var exist, stat, err = c.Exist(p)
+		if err != nil {
+			return "", err
+		}
+		if exist && stat.EphemeralOwner == session {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// protectedCreation creates a znode whose name is prefixed with a GUID.
+//
+// The reply of a creation may be lost along with the connection after the
+// znode is created, so the parent is searched for the prefix before every
+// further attempt. It's retried regardless of the RetryPolicy, otherwise the
+// znode created would be left behind.
+type protectedCreation struct {
+	ctx      context.Context
+	prefix   string
+	interval time.Duration
+	// create creates the znode and returns its path.
+	create func() (string, error)
+	// children returns names of the children of the parent.
+	children func() ([]string, error)
+}
+
+// run creates the znode within maxProtectedAttempts, the path created or
+// found is returned.
+func (pc *protectedCreation) run() (string, error) {
+	var err error
+	for attempt := 0; attempt < maxProtectedAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(pc.interval):
+			case <-pc.ctx.Done():
+				return "", pc.ctx.Err()
+			}
+			var found string
+			found, err = pc.find()
+			if IsRetryableErr(err) {
+				continue
+			}
+			if err != nil || found != "" {
+				return found, err
+			}
+		}
+		var created string
+		created, err = pc.create()
+		if !IsRetryableErr(err) {
+			return created, err
+		}
+	}
+	return "", err
+}
+
+// find returns the name of the child named with the prefix, "" is returned
+// if not found.
+func (pc *protectedCreation) find() (string, error) {
+	var children, err = pc.children()
+	if err != nil {
+		return "", err
+	}
+	for _, child := range children {
+		if strings.HasPrefix(child, pc.prefix) {
+			return child, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,110 @@
+package enhanced
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// fakeParent is a parent znode whose creations fail with given errors.
+type fakeParent struct {
+	children []string
+	// errs are returned by creations in order, the znode is created before
+	// the error is returned if created is true.
+	errs    []error
+	created []bool
+	creates int
+	lists   int
+}
+
+func (f *fakeParent) protectedCreation(ctx context.Context) *protectedCreation {
+	return &protectedCreation{
+		ctx:    ctx,
+		prefix: protectedNamePrefix("guid"),
+		create: func() (string, error) {
+			var i = f.creates
+			f.creates++
+			if i < len(f.errs) {
+				if f.created[i] {
+					f.children = append(f.children, "_c_guid-node0000000001")
+				}
+				return "", f.errs[i]
+			}
+			f.children = append(f.children, "_c_guid-node0000000001")
+			return "/dir/_c_guid-node0000000001", nil
+		},
+		children: func() ([]string, error) {
+			f.lists++
+			return f.children, nil
+		},
+	}
+}
+
+func TestProtectedCreation(t *testing.T) {
+	var f = &fakeParent{}
+	var created, err = f.protectedCreation(context.Background()).run()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/dir/_c_guid-node0000000001", created)
+	assert.Equal(t, 1, f.creates)
+	assert.Equal(t, 0, f.lists)
+}
+
+func TestProtectedCreationConnectionLostAfterCreate(t *testing.T) {
+	var f = &fakeParent{
+		children: []string{"other0000000000"},
+		errs:     []error{zk.ErrConnectionClosed},
+		created:  []bool{true},
+	}
+	var created, err = f.protectedCreation(context.Background()).run()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "_c_guid-node0000000001", created)
+	// Found instead of created again.
+	assert.Equal(t, 1, f.creates)
+	assert.Equal(t, []string{"other0000000000", "_c_guid-node0000000001"}, f.children)
+}
+
+func TestProtectedCreationConnectionLostBeforeCreate(t *testing.T) {
+	var f = &fakeParent{
+		errs:    []error{zk.ErrConnectionClosed},
+		created: []bool{false},
+	}
+	var created, err = f.protectedCreation(context.Background()).run()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/dir/_c_guid-node0000000001", created)
+	assert.Equal(t, 2, f.creates)
+	assert.Equal(t, 1, f.lists)
+}
+
+func TestProtectedCreationGivesUp(t *testing.T) {
+	var f = &fakeParent{
+		errs:    []error{zk.ErrConnectionClosed, zk.ErrConnectionClosed, zk.ErrConnectionClosed, nil},
+		created: []bool{false, false, false, false},
+	}
+	var _, err = f.protectedCreation(context.Background()).run()
+	assert.Equal(t, zk.ErrConnectionClosed, err)
+	assert.Equal(t, maxProtectedAttempts, f.creates)
+}
+
+func TestProtectedCreationError(t *testing.T) {
+	var boom = errors.New("boom")
+	var f = &fakeParent{errs: []error{boom}, created: []bool{false}}
+	var _, err = f.protectedCreation(context.Background()).run()
+	assert.Equal(t, boom, err)
+	assert.Equal(t, 1, f.creates)
+
+	f = &fakeParent{errs: []error{zk.ErrConnectionClosed}, created: []bool{false}}
+	var ctx, cancel = context.WithCancel(context.Background())
+	var pc = f.protectedCreation(ctx)
+	pc.interval = protectedRetryInterval
+	cancel()
+	_, err = pc.run()
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, f.creates)
+}
+
+func TestProtectedName(t *testing.T) {
+	assert.Equal(t, "_c_guid-node-", ProtectedName("guid", "node-"))
+}
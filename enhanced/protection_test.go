@@ -0,0 +1,40 @@
+package enhanced_test
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func TestProtectedCreationConnectionLoss(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		// No RetryPolicy is set, protected creations are retried anyway.
+		var client, proxy = env.NewProxiedClientTimeout(time.Second * 2)
+		for i := 0; i < 5; i++ {
+			var dir = fmt.Sprintf("/protected/%d", i)
+			_, err := env.Client().CreateWithParents(dir)
+			assert.NoError(t, err)
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				proxy.Interrupt(time.Millisecond * 200)
+			}()
+			created, err := client.CreateValue(path.Join(dir, "node"), nil,
+				enhanced.WithMode(enhanced.ModeSequential), enhanced.WithProtection())
+			wg.Wait()
+			assert.NoError(t, err)
+
+			children, _, err := env.Client().GetChildren(dir)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{path.Base(created)}, children)
+		}
+	})
+}
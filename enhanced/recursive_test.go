@@ -0,0 +1,106 @@
+package enhanced_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestDeleteWithChildrenBeyondMultiLimit(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		for i := 0; i < 30; i++ {
+			for j := 0; j < 10; j++ {
+				_, err := client.CreateWithParents(fmt.Sprintf("/root/%d/%d", i, j))
+				assert.NoError(t, err)
+			}
+		}
+
+		assert.NoError(t, client.DeleteWithChildren("/root"))
+		exists, _, err := client.Exist("/root")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, zk.ErrNoNode, client.DeleteWithChildren("/root"))
+	})
+}
+
+func TestCreateWithParentsDeep(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		var p string
+		for i := 0; i < 120; i++ {
+			p += fmt.Sprintf("/%d", i)
+		}
+		created, err := client.CreateValueWithParents(p, []byte("leaf"))
+		assert.NoError(t, err)
+		assert.Equal(t, p, created)
+
+		value, _, err := client.Get(p)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("leaf"), value)
+		assert.NoError(t, client.DeleteWithChildren("/0"))
+	})
+}
+
+func TestCreateWithParentsConcurrently(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		const n = 10
+		var wg sync.WaitGroup
+		var errs = make(chan error, n)
+		for i := 0; i < n; i++ {
+			var client = env.NewClient()
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var _, err = client.CreateValueWithParents(fmt.Sprintf("/a/b/c/d/%d", i), []byte("v"))
+				errs <- err
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		children, _, err := env.Client().GetChildren("/a/b/c/d")
+		assert.NoError(t, err)
+		assert.Len(t, children, n)
+	})
+}
+
+func TestDeleteWithChildrenWhileAdding(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		for i := 0; i < 20; i++ {
+			_, err := client.CreateWithParents(fmt.Sprintf("/parent/%d", i))
+			assert.NoError(t, err)
+		}
+
+		var adder = env.NewClient()
+		var done = make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 20; i < 200; i++ {
+				// Fails with ErrNoNode once the parent is deleted.
+				if _, err := adder.Create(fmt.Sprintf("/parent/%d", i)); err == zk.ErrNoNode {
+					return
+				}
+			}
+		}()
+
+		var err = client.DeleteWithChildren("/parent")
+		<-done
+		if err == zk.ErrNotEmpty {
+			// Children kept coming during all attempts, the adder is done now.
+			err = client.DeleteWithChildren("/parent")
+		}
+		assert.NoError(t, err)
+		exists, _, err := client.Exist("/parent")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
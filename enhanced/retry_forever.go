@@ -0,0 +1,18 @@
+package enhanced
+
+import "time"
+
+// RetryForever always retries with a fixed sleep time between retries.
+type RetryForever struct {
+	sleep time.Duration
+}
+
+// NewRetryForever creates RetryForever.
+func NewRetryForever(sleep time.Duration) *RetryForever {
+	return &RetryForever{sleep: sleep}
+}
+
+// AllowRetry implements RetryPolicy.
+func (r *RetryForever) AllowRetry(retries int, elapsed time.Duration) (time.Duration, bool) {
+	return r.sleep, true
+}
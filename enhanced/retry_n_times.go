@@ -0,0 +1,19 @@
+package enhanced
+
+import "time"
+
+// RetryNTimes retries a max number of times with a fixed sleep time between retries.
+type RetryNTimes struct {
+	n     int
+	sleep time.Duration
+}
+
+// NewRetryNTimes creates RetryNTimes.
+func NewRetryNTimes(n int, sleep time.Duration) *RetryNTimes {
+	return &RetryNTimes{n: n, sleep: sleep}
+}
+
+// AllowRetry implements RetryPolicy.
+func (r *RetryNTimes) AllowRetry(retries int, elapsed time.Duration) (time.Duration, bool) {
+	return r.sleep, retries < r.n
+}
@@ -0,0 +1,27 @@
+package enhanced
+
+import (
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// RetryPolicy decides whether a failed operation should be retried.
+type RetryPolicy interface {
+	// AllowRetry is called when an operation fails with a retryable error.
+	// retries is the number of retries done so far, starting from 0, and
+	// elapsed is the time since the first attempt.
+	// It returns the duration to sleep before the next attempt and whether
+	// the attempt is allowed.
+	AllowRetry(retries int, elapsed time.Duration) (time.Duration, bool)
+}
+
+// IsRetryableErr returns true if an operation failed with err is worth retrying.
+func IsRetryableErr(err error) bool {
+	switch err {
+	case zk.ErrConnectionClosed, zk.ErrSessionMoved:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,154 @@
+package enhanced
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestRetryNTimes(t *testing.T) {
+	var p = NewRetryNTimes(2, time.Millisecond)
+	for retries, allowed := range []bool{true, true, false} {
+		sleep, ok := p.AllowRetry(retries, 0)
+		assert.Equal(t, allowed, ok)
+		assert.Equal(t, time.Millisecond, sleep)
+	}
+}
+
+func TestRetryUntilElapsed(t *testing.T) {
+	var p = NewRetryUntilElapsed(time.Second, time.Millisecond)
+	_, ok := p.AllowRetry(100, time.Second-time.Millisecond)
+	assert.Equal(t, true, ok)
+	_, ok = p.AllowRetry(0, time.Second)
+	assert.Equal(t, false, ok)
+}
+
+func TestRetryForever(t *testing.T) {
+	_, ok := NewRetryForever(time.Millisecond).AllowRetry(1<<30, time.Hour)
+	assert.Equal(t, true, ok)
+}
+
+func TestExponentialBackoffRetry(t *testing.T) {
+	var p = NewExponentialBackoffRetry(time.Millisecond, 3).SetMaxSleep(3 * time.Millisecond)
+	for retries := 0; retries < 3; retries++ {
+		sleep, ok := p.AllowRetry(retries, 0)
+		assert.Equal(t, true, ok)
+		assert.T(t, sleep >= time.Millisecond && sleep <= 3*time.Millisecond, sleep)
+	}
+	_, ok := p.AllowRetry(3, 0)
+	assert.Equal(t, false, ok)
+
+	sleep, _ := NewExponentialBackoffRetry(time.Millisecond, 100).AllowRetry(99, 0)
+	assert.T(t, sleep > 0, sleep)
+}
+
+func TestRetryRetryableErr(t *testing.T) {
+	var o = newBasicOperations(nil)
+	var calls int
+	var err = o.retry(o.newOpOptions([]OpOption{WithRetryPolicy(NewRetryNTimes(5, 0))}), func() error {
+		calls++
+		if calls < 3 {
+			return zk.ErrConnectionClosed
+		}
+		return nil
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryGiveUp(t *testing.T) {
+	var o = newBasicOperations(nil)
+	o.SetRetryPolicy(NewRetryNTimes(2, 0))
+	var calls int
+	var err = o.retry(o.newOpOptions(nil), func() error {
+		calls++
+		return zk.ErrSessionMoved
+	})
+	assert.Equal(t, zk.ErrSessionMoved, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryNotRetryableErr(t *testing.T) {
+	var o = newBasicOperations(nil)
+	o.SetRetryPolicy(NewRetryForever(0))
+	var calls int
+	var expected = errors.New("not retryable")
+	var err = o.retry(o.newOpOptions(nil), func() error {
+		calls++
+		return expected
+	})
+	assert.Equal(t, expected, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryDisabledPerCall(t *testing.T) {
+	var o = newBasicOperations(nil)
+	o.SetRetryPolicy(NewRetryForever(0))
+	var calls int
+	var err = o.retry(o.newOpOptions([]OpOption{WithRetryPolicy(nil)}), func() error {
+		calls++
+		return zk.ErrConnectionClosed
+	})
+	assert.Equal(t, zk.ErrConnectionClosed, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryCtxDone(t *testing.T) {
+	var o = newBasicOperations(nil)
+	o.SetRetryPolicy(NewRetryForever(time.Hour))
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	var err = o.retry(o.newCtxOpOptions(ctx, nil), func() error {
+		return zk.ErrConnectionClosed
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestCallCtxCanceled(t *testing.T) {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var block = make(chan struct{})
+	defer close(block)
+	go cancel()
+	var err = callCtx(ctx, func() error {
+		<-block
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWithoutRetry(t *testing.T) {
+	var o = newBasicOperations(nil)
+	o.SetRetryPolicy(NewRetryForever(0))
+	var opt = o.newOpOptions(nil)
+	var calls int
+	var err = o.retry(opt.withoutRetry(), func() error {
+		calls++
+		return zk.ErrConnectionClosed
+	})
+	assert.Equal(t, zk.ErrConnectionClosed, err)
+	assert.Equal(t, 1, calls)
+	assert.NotEqual(t, nil, opt.retryPolicy)
+}
+
+func TestRetriedDeletion(t *testing.T) {
+	var o = newBasicOperations(nil)
+	o.SetRetryPolicy(NewRetryForever(0))
+	var errs = []error{zk.ErrConnectionClosed, zk.ErrNoNode}
+	var calls int
+	var err = o.retry(o.newOpOptions(nil), retriedDeletion(func() error {
+		calls++
+		return errs[calls-1]
+	}))
+	// Deleted by the first attempt.
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, calls)
+
+	err = o.retry(o.newOpOptions(nil), retriedDeletion(func() error {
+		return zk.ErrNoNode
+	}))
+	assert.Equal(t, zk.ErrNoNode, err)
+}
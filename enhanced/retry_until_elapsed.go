@@ -0,0 +1,19 @@
+package enhanced
+
+import "time"
+
+// RetryUntilElapsed retries until a given amount of time elapses.
+type RetryUntilElapsed struct {
+	maxElapsed time.Duration
+	sleep      time.Duration
+}
+
+// NewRetryUntilElapsed creates RetryUntilElapsed.
+func NewRetryUntilElapsed(maxElapsed, sleep time.Duration) *RetryUntilElapsed {
+	return &RetryUntilElapsed{maxElapsed: maxElapsed, sleep: sleep}
+}
+
+// AllowRetry implements RetryPolicy.
+func (r *RetryUntilElapsed) AllowRetry(retries int, elapsed time.Duration) (time.Duration, bool) {
+	return r.sleep, elapsed < r.maxElapsed
+}
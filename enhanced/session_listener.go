@@ -0,0 +1,16 @@
+package enhanced
+
+// SessionListener is a handler of session creation.
+type SessionListener struct {
+	fn func(int64)
+}
+
+// Handle calls the function with sessionID.
+func (l *SessionListener) Handle(sessionID int64) {
+	l.fn(sessionID)
+}
+
+// NewSessionListener creates SessionListener from fn.
+func NewSessionListener(fn func(int64)) *SessionListener {
+	return &SessionListener{fn}
+}
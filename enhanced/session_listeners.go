@@ -0,0 +1,28 @@
+package enhanced
+
+// SessionListeners is a container of SessionListeners.
+type SessionListeners struct {
+	*ListenerContainer
+}
+
+// NewSessionListeners creates empty SessionListeners.
+func NewSessionListeners() *SessionListeners {
+	return &SessionListeners{NewListenerContainer()}
+}
+
+// Add adds a Listener.
+func (l *SessionListeners) Add(listener *SessionListener) {
+	l.ListenerContainer.Add(listener)
+}
+
+// Del deletes a Listener.
+func (l *SessionListeners) Del(listener *SessionListener) {
+	l.ListenerContainer.Del(listener)
+}
+
+// Broadcast calls every listener with given session ID.
+func (l *SessionListeners) Broadcast(sessionID int64) {
+	l.ListenerContainer.Foreach(func(listener interface{}) {
+		listener.(*SessionListener).Handle(sessionID)
+	})
+}
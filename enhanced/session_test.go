@@ -0,0 +1,78 @@
+package enhanced_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func TestRecreateSessionOnExpire(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client, proxy = env.NewProxiedClientTimeout(time.Second * 2)
+		client.SetRecreateSessionOnExpire(true)
+		assert.NoError(t, client.SetDigestAuth([]byte("user:password")))
+		_, err := client.CreateValue("/secret", []byte("s"),
+			enhanced.WithACL(zk.DigestACL(zk.PermAll, "user", "password")))
+		assert.NoError(t, err)
+
+		var states = make(chan enhanced.ConnState, 10)
+		client.AddStateChangeListener(enhanced.NewStateChangeListener(func(s enhanced.ConnState) {
+			states <- s
+		}))
+		var sessions = make(chan int64, 1)
+		var secrets = make(chan error, 1)
+		client.AddSessionListener(enhanced.NewSessionListener(func(id int64) {
+			// Operations issued right after renewal must be authorized.
+			var _, _, err = client.Get("/secret")
+			secrets <- err
+			sessions <- id
+		}))
+		var oldSession = client.Conn().SessionID()
+
+		proxy.ExpireSession()
+		select {
+		case id := <-sessions:
+			assert.NotEqual(t, oldSession, id)
+			assert.Equal(t, id, client.Conn().SessionID())
+		case <-time.After(time.Second * 10):
+			t.Fatal("Waiting for new session timed out")
+		}
+		assert.NoError(t, <-secrets)
+
+		var got []enhanced.ConnState
+		for len(states) > 0 {
+			got = append(got, <-states)
+		}
+		assert.Equal(t, []enhanced.ConnState{
+			enhanced.ConnStateSuspended,
+			enhanced.ConnStateLost,
+			enhanced.ConnStateReconnected,
+		}, got)
+		assert.True(t, client.IsConnected())
+	})
+}
+
+func TestSessionNotRecreatedByDefault(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client, proxy = env.NewProxiedClientTimeout(time.Second * 2)
+		var conn = client.Conn()
+		var sessions = make(chan int64, 1)
+		client.AddSessionListener(enhanced.NewSessionListener(func(id int64) {
+			sessions <- id
+		}))
+
+		proxy.ExpireSession()
+		assert.True(t, client.BlockUntilConnected(time.Second*10))
+		// zk.Conn creates a new session by itself.
+		assert.Equal(t, conn, client.Conn())
+		select {
+		case <-sessions:
+		case <-time.After(time.Second * 5):
+			t.Fatal("Waiting for new session timed out")
+		}
+	})
+}
@@ -0,0 +1,32 @@
+//go:build go1.21
+// +build go1.21
+
+package enhanced
+
+import "log/slog"
+
+// slogLogger adapts *slog.Logger to Logger.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger creates a Logger writing messages to l.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.l.Debug(msg, keysAndValues...)
+}
+
+func (s *slogLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.l.Info(msg, keysAndValues...)
+}
+
+func (s *slogLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.l.Warn(msg, keysAndValues...)
+}
+
+func (s *slogLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.l.Error(msg, keysAndValues...)
+}
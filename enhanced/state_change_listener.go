@@ -0,0 +1,16 @@
+package enhanced
+
+// StateChangeListener is a handler of ConnState transitions.
+type StateChangeListener struct {
+	fn func(ConnState)
+}
+
+// Handle calls the function with s.
+func (l *StateChangeListener) Handle(s ConnState) {
+	l.fn(s)
+}
+
+// NewStateChangeListener creates StateChangeListener from fn.
+func NewStateChangeListener(fn func(ConnState)) *StateChangeListener {
+	return &StateChangeListener{fn}
+}
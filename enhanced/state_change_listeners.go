@@ -0,0 +1,28 @@
+package enhanced
+
+// StateChangeListeners is a container of StateChangeListeners.
+type StateChangeListeners struct {
+	*ListenerContainer
+}
+
+// NewStateChangeListeners creates empty StateChangeListeners.
+func NewStateChangeListeners() *StateChangeListeners {
+	return &StateChangeListeners{NewListenerContainer()}
+}
+
+// Add adds a Listener.
+func (l *StateChangeListeners) Add(listener *StateChangeListener) {
+	l.ListenerContainer.Add(listener)
+}
+
+// Del deletes a Listener.
+func (l *StateChangeListeners) Del(listener *StateChangeListener) {
+	l.ListenerContainer.Del(listener)
+}
+
+// Broadcast calls every listener with given state.
+func (l *StateChangeListeners) Broadcast(s ConnState) {
+	l.ListenerContainer.Foreach(func(listener interface{}) {
+		listener.(*StateChangeListener).Handle(s)
+	})
+}
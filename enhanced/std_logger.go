@@ -0,0 +1,51 @@
+package enhanced
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+// stdLogger adapts *log.Logger to Logger.
+type stdLogger struct {
+	l        *log.Logger
+	minLevel LogLevel
+}
+
+// NewStdLogger creates a Logger writing messages at or above minLevel to l.
+// Messages are formatted like: [INFO] msg key1=value1 key2=value2
+func NewStdLogger(l *log.Logger, minLevel LogLevel) Logger {
+	return &stdLogger{l: l, minLevel: minLevel}
+}
+
+func (s *stdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	s.log(LogLevelDebug, msg, keysAndValues)
+}
+
+func (s *stdLogger) Info(msg string, keysAndValues ...interface{}) {
+	s.log(LogLevelInfo, msg, keysAndValues)
+}
+
+func (s *stdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	s.log(LogLevelWarn, msg, keysAndValues)
+}
+
+func (s *stdLogger) Error(msg string, keysAndValues ...interface{}) {
+	s.log(LogLevelError, msg, keysAndValues)
+}
+
+func (s *stdLogger) log(level LogLevel, msg string, keysAndValues []interface{}) {
+	if level < s.minLevel {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "[%s] %s", level, msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fmt.Fprintf(&buf, " %v=%v", keysAndValues[i], value)
+	}
+	s.l.Output(3, buf.String())
+}
@@ -0,0 +1,31 @@
+package enhanced
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestStdLoggerFormat(t *testing.T) {
+	var buf bytes.Buffer
+	var l = NewStdLogger(log.New(&buf, "", 0), LogLevelDebug)
+	l.Warn("retrying", "path", "/a", "err", errors.New("boom"))
+	assert.Equal(t, "[WARN] retrying path=/a err=boom\n", buf.String())
+
+	buf.Reset()
+	l.Info("odd", "key")
+	assert.Equal(t, "[INFO] odd key=(MISSING)\n", buf.String())
+}
+
+func TestStdLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	var l = NewStdLogger(log.New(&buf, "", 0), LogLevelWarn)
+	l.Debug("debug")
+	l.Info("info")
+	assert.Equal(t, "", buf.String())
+	l.Error("error")
+	assert.Equal(t, "[ERROR] error\n", buf.String())
+}
@@ -0,0 +1,123 @@
+package enhanced
+
+import (
+	"context"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+// Tx is a builder of atomic multi-op transaction.
+// All operations either succeed or fail together once committed.
+// Paths are relative to the namespace of the client.
+type Tx struct {
+	ops   *nsBasicOperations
+	types []TxOpType
+	reqs  []interface{}
+	// err is the first error of building operations, it's returned by Commit.
+	err error
+}
+
+// Tx begins a transaction.
+func (nb *nsBasicOperations) Tx() *Tx {
+	return &Tx{ops: nb}
+}
+
+// Create adds an operation creating given znode with value.
+// The flags and ACL of the client are used unless overridden by WithMode and
+// WithACL, other options are ignored.
+func (t *Tx) Create(p string, value []byte, opts ...OpOption) *Tx {
+	var opt = t.ops.newOpOptions(opts)
+	var flags, err = opt.createFlags()
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+	return t.add(TxOpCreate, &zk.CreateRequest{
+		Path:  t.ops.namespaced(p),
+		Data:  value,
+		Acl:   opt.acl,
+		Flags: flags,
+	})
+}
+
+// SetData adds an operation setting the value on given znode.
+func (t *Tx) SetData(p string, value []byte, version int32) *Tx {
+	return t.add(TxOpSetData, &zk.SetDataRequest{
+		Path:    t.ops.namespaced(p),
+		Data:    value,
+		Version: version,
+	})
+}
+
+// Delete adds an operation deleting given znode.
+func (t *Tx) Delete(p string, version int32) *Tx {
+	return t.add(TxOpDelete, &zk.DeleteRequest{
+		Path:    t.ops.namespaced(p),
+		Version: version,
+	})
+}
+
+// Check adds an operation asserting the version of given znode.
+func (t *Tx) Check(p string, version int32) *Tx {
+	return t.add(TxOpCheck, &zk.CheckVersionRequest{
+		Path:    t.ops.namespaced(p),
+		Version: version,
+	})
+}
+
+func (t *Tx) add(tp TxOpType, req interface{}) *Tx {
+	t.types = append(t.types, tp)
+	t.reqs = append(t.reqs, req)
+	return t
+}
+
+// Commit executes all operations atomically.
+// The results are in the same order as the operations were added, the error
+// returned is the first error of the operations if any.
+// It's not retried regardless of the RetryPolicy, since operations might have
+// been applied before the connection was lost.
+func (t *Tx) Commit(opts ...OpOption) ([]TxResult, error) {
+	return t.CommitCtx(context.Background(), opts...)
+}
+
+// CommitCtx is Commit with a context.
+func (t *Tx) CommitCtx(ctx context.Context, opts ...OpOption) ([]TxResult, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if len(t.reqs) == 0 {
+		return nil, nil
+	}
+	var responses, err = t.ops.multi(t.ops.newCtxOpOptions(ctx, opts).withoutRetry(), t.reqs...)
+	if responses == nil {
+		return nil, err
+	}
+	var results = make([]TxResult, len(t.reqs))
+	for i, res := range responses {
+		results[i] = TxResult{
+			Type: t.types[i],
+			Path: t.ops.unnamespaced(t.reqPath(i)),
+			Stat: res.Stat,
+			Err:  res.Error,
+		}
+		if t.types[i] == TxOpCreate && res.Error == nil {
+			results[i].Path = t.ops.unnamespaced(res.String)
+		}
+	}
+	return results, err
+}
+
+// reqPath returns the path of the ith operation.
+func (t *Tx) reqPath(i int) string {
+	switch req := t.reqs[i].(type) {
+	case *zk.CreateRequest:
+		return req.Path
+	case *zk.SetDataRequest:
+		return req.Path
+	case *zk.DeleteRequest:
+		return req.Path
+	case *zk.CheckVersionRequest:
+		return req.Path
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,103 @@
+package enhanced_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func TestTxCommitNamespaced(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClient().SetNamespace("ns")
+		_, err := client.CreateValueWithParents("/b", []byte("b"))
+		assert.NoError(t, err)
+
+		results, err := client.Tx().
+			Create("/a", []byte("a")).
+			SetData("/b", []byte("bb"), -1).
+			Check("/b", 1).
+			Delete("/b", 1).
+			Commit()
+		assert.NoError(t, err)
+		assert.Len(t, results, 4)
+		for i, tp := range []enhanced.TxOpType{enhanced.TxOpCreate, enhanced.TxOpSetData, enhanced.TxOpCheck, enhanced.TxOpDelete} {
+			assert.Equal(t, tp, results[i].Type)
+			assert.NoError(t, results[i].Err)
+		}
+		assert.Equal(t, "/a", results[0].Path)
+		assert.Equal(t, "/b", results[1].Path)
+
+		value, _, err := env.Client().Get("/ns/a")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("a"), value)
+		exists, _, err := env.Client().Exist("/ns/b")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestTxCommitPartialFailure(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		results, err := client.Tx().
+			Create("/a", nil).
+			SetData("/missing", nil, -1).
+			Commit()
+		assert.Equal(t, zk.ErrNoNode, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, "/a", results[0].Path)
+		assert.Error(t, results[0].Err)
+		assert.Equal(t, "/missing", results[1].Path)
+		assert.Equal(t, zk.ErrNoNode, results[1].Err)
+
+		// Nothing is applied.
+		exists, _, err := client.Exist("/a")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestTxCommitNotRetried(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client, proxy = env.NewProxiedClientTimeout(time.Second * 2)
+		client.SetRetryPolicy(enhanced.NewRetryNTimes(5, time.Millisecond*100))
+		client.SetFlags(zk.FlagSequence)
+		_, err := env.Client().Create("/seq")
+		assert.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				proxy.Interrupt(time.Millisecond * 200)
+			}()
+			var prefix = fmt.Sprintf("n%d-", i)
+			var results, err = client.Tx().Create("/seq/"+prefix, nil).Commit()
+			wg.Wait()
+			assert.True(t, client.BlockUntilConnected(time.Second*5))
+
+			children, _, cErr := env.Client().GetChildren("/seq")
+			assert.NoError(t, cErr)
+			var created []string
+			for _, child := range children {
+				if strings.HasPrefix(child, prefix) {
+					created = append(created, child)
+				}
+			}
+			// A lost reply must not lead to duplicated sequential nodes.
+			assert.True(t, len(created) <= 1, "created: %v", created)
+			if err == nil {
+				assert.Len(t, created, 1)
+				assert.Equal(t, "/seq/"+created[0], results[0].Path)
+			}
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package enhanced
+
+import "github.com/samuel/go-zookeeper/zk"
+
+// TxOpType represents the type of an operation in a Tx.
+type TxOpType int
+
+const (
+	// TxOpCreate creates a znode.
+	TxOpCreate TxOpType = iota
+	// TxOpSetData sets the value of a znode.
+	TxOpSetData
+	// TxOpDelete deletes a znode.
+	TxOpDelete
+	// TxOpCheck checks the version of a znode.
+	TxOpCheck
+)
+
+// String returns the string representation of TxOpType.
+// "Unknown" is returned when the type is unknown.
+func (t TxOpType) String() string {
+	switch t {
+	case TxOpCreate:
+		return "Create"
+	case TxOpSetData:
+		return "SetData"
+	case TxOpDelete:
+		return "Delete"
+	case TxOpCheck:
+		return "Check"
+	default:
+		return "Unknown"
+	}
+}
+
+// TxResult contains the result of an operation in a Tx.
+type TxResult struct {
+	Type TxOpType
+	// Path is the path of the znode, for TxOpCreate it's the path actually
+	// created which differs from the requested one for sequential znodes.
+	Path string
+	// Stat is only available for TxOpSetData.
+	Stat *zk.Stat
+	Err  error
+}
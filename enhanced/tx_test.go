@@ -0,0 +1,44 @@
+package enhanced
+
+import (
+	"testing"
+
+	"github.com/bmizerany/assert"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestTxNamespaced(t *testing.T) {
+	var c = newClient(nil, nil).SetNamespace("prefix")
+	var tx = c.Tx().Create("/a", nil).SetData("/b", nil, 1).Delete("/c", 2).Check("/d", 3)
+	assert.Equal(t, []TxOpType{TxOpCreate, TxOpSetData, TxOpDelete, TxOpCheck}, tx.types)
+	for i, p := range []string{"/prefix/a", "/prefix/b", "/prefix/c", "/prefix/d"} {
+		assert.Equal(t, p, tx.reqPath(i))
+	}
+}
+
+func TestTxCreateFlags(t *testing.T) {
+	var c = newClient(nil, nil)
+	c.SetFlags(zk.FlagEphemeral)
+	var req = c.Tx().Create("/a", []byte("x")).reqs[0].(*zk.CreateRequest)
+	assert.Equal(t, int32(zk.FlagEphemeral), req.Flags)
+	assert.Equal(t, c.acl, req.Acl)
+}
+
+func TestTxCreateOptions(t *testing.T) {
+	var c = newClient(nil, nil)
+	c.SetFlags(zk.FlagEphemeral)
+	var acl = zk.DigestACL(zk.PermRead, "user", "password")
+	var req = c.Tx().Create("/a", nil, WithMode(ModeSequential), WithACL(acl)).reqs[0].(*zk.CreateRequest)
+	assert.Equal(t, int32(zk.FlagSequence), req.Flags)
+	assert.Equal(t, acl, req.Acl)
+
+	var results, err = c.Tx().Create("/a", nil, WithMode(1<<2)).Commit()
+	assert.Equal(t, 0, len(results))
+	assert.Equal(t, ErrInvalidCreateMode, err)
+}
+
+func TestTxCommitEmpty(t *testing.T) {
+	var results, err = newClient(nil, nil).Tx().Commit()
+	assert.Equal(t, 0, len(results))
+	assert.Equal(t, nil, err)
+}
@@ -1,6 +1,10 @@
 package enhanced
 
-import "github.com/samuel/go-zookeeper/zk"
+import (
+	"context"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
 
 // watchOperations contains APIs of watching ZNode.
 type watchOperations struct {
@@ -21,14 +25,31 @@ func newWatchOperations(ns *namespace, closed chan struct{}, conner Conner) watc
 // processResult will be called after watching.
 // processEvent will be called with further event ONCE.
 func (w *watchOperations) WatchChildren(p string, processResult func(ChildrenResult), processEvent func(zk.Event)) {
+	w.WatchChildrenCtx(context.Background(), p, processResult, processEvent)
+}
+
+// WatchChildrenCtx is WatchChildren with a context.
+// processResult will be called with the error of ctx if ctx is done before
+// the result arrives, processEvent will not be called once ctx is done.
+func (w *watchOperations) WatchChildrenCtx(ctx context.Context, p string, processResult func(ChildrenResult), processEvent func(zk.Event)) {
 	go func() {
-		children, stat, change, err := w.Conn().ChildrenW(w.namespaced(p))
+		var children []string
+		var stat *zk.Stat
+		var change <-chan zk.Event
+		var err = callCtx(ctx, func() (err error) {
+			children, stat, change, err = w.Conn().ChildrenW(w.namespaced(p))
+			return
+		})
+		if err != nil && err == ctx.Err() {
+			processResult(ChildrenResult{Path: p, Err: err})
+			return
+		}
 		processResult(ChildrenResult{
 			Path:     p,
 			Children: children,
 			Stat:     stat,
 			Err:      err})
-		w.waitForEvent(change, processEvent)
+		w.waitForEvent(ctx, change, processEvent)
 	}()
 }
 
@@ -36,14 +57,31 @@ func (w *watchOperations) WatchChildren(p string, processResult func(ChildrenRes
 // processResult will be called after watching.
 // processEvent will be called with further event ONCE.
 func (w *watchOperations) WatchData(p string, processResult func(DataResult), processEvent func(zk.Event)) {
+	w.WatchDataCtx(context.Background(), p, processResult, processEvent)
+}
+
+// WatchDataCtx is WatchData with a context.
+// processResult will be called with the error of ctx if ctx is done before
+// the result arrives, processEvent will not be called once ctx is done.
+func (w *watchOperations) WatchDataCtx(ctx context.Context, p string, processResult func(DataResult), processEvent func(zk.Event)) {
 	go func() {
-		data, stat, change, err := w.Conn().GetW(w.namespaced(p))
+		var data []byte
+		var stat *zk.Stat
+		var change <-chan zk.Event
+		var err = callCtx(ctx, func() (err error) {
+			data, stat, change, err = w.Conn().GetW(w.namespaced(p))
+			return
+		})
+		if err != nil && err == ctx.Err() {
+			processResult(DataResult{Path: p, Err: err})
+			return
+		}
 		processResult(DataResult{
 			Path: p,
 			Data: data,
 			Stat: stat,
 			Err:  err})
-		w.waitForEvent(change, processEvent)
+		w.waitForEvent(ctx, change, processEvent)
 	}()
 }
 
@@ -51,21 +89,39 @@ func (w *watchOperations) WatchData(p string, processResult func(DataResult), pr
 // processResult will be called after watching.
 // processEvent will be called with further event ONCE.
 func (w *watchOperations) WatchExist(p string, processResult func(ExistResult), processEvent func(zk.Event)) {
+	w.WatchExistCtx(context.Background(), p, processResult, processEvent)
+}
+
+// WatchExistCtx is WatchExist with a context.
+// processResult will be called with the error of ctx if ctx is done before
+// the result arrives, processEvent will not be called once ctx is done.
+func (w *watchOperations) WatchExistCtx(ctx context.Context, p string, processResult func(ExistResult), processEvent func(zk.Event)) {
 	go func() {
-		exist, stat, change, err := w.Conn().ExistsW(w.namespaced(p))
+		var exist bool
+		var stat *zk.Stat
+		var change <-chan zk.Event
+		var err = callCtx(ctx, func() (err error) {
+			exist, stat, change, err = w.Conn().ExistsW(w.namespaced(p))
+			return
+		})
+		if err != nil && err == ctx.Err() {
+			processResult(ExistResult{Path: p, Err: err})
+			return
+		}
 		processResult(ExistResult{
 			Exist: exist,
 			Path:  p,
 			Stat:  stat,
 			Err:   err})
-		w.waitForEvent(change, processEvent)
+		w.waitForEvent(ctx, change, processEvent)
 	}()
 }
 
-func (w *watchOperations) waitForEvent(ch <-chan zk.Event, processEvent func(zk.Event)) {
+func (w *watchOperations) waitForEvent(ctx context.Context, ch <-chan zk.Event, processEvent func(zk.Event)) {
 	select {
 	case evt := <-ch:
 		processEvent(evt)
+	case <-ctx.Done():
 	case <-w.closed:
 		// TODO: send event to eventCallback?
 	}
@@ -0,0 +1,11 @@
+package atomic
+
+// AtomicValue is the result of an atomic operation.
+type AtomicValue struct {
+	// Succeeded indicates whether the operation succeeded.
+	Succeeded bool
+	// PreValue is the value before the operation.
+	PreValue int64
+	// PostValue is the value after the operation if it succeeded.
+	PostValue int64
+}
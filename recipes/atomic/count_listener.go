@@ -0,0 +1,16 @@
+package atomic
+
+// CountListener is a handler of count changes.
+type CountListener struct {
+	fn func(int64)
+}
+
+// Handle calls the function with the new count.
+func (l *CountListener) Handle(count int64) {
+	l.fn(count)
+}
+
+// NewCountListener creates CountListener from fn.
+func NewCountListener(fn func(count int64)) *CountListener {
+	return &CountListener{fn}
+}
@@ -0,0 +1,30 @@
+package atomic
+
+import "github.com/tevino/zoo/enhanced"
+
+// CountListeners is a container of CountListeners.
+type CountListeners struct {
+	*enhanced.ListenerContainer
+}
+
+// NewCountListeners creates empty CountListeners.
+func NewCountListeners() *CountListeners {
+	return &CountListeners{enhanced.NewListenerContainer()}
+}
+
+// Add adds a Listener.
+func (l *CountListeners) Add(listener *CountListener) {
+	l.ListenerContainer.Add(listener)
+}
+
+// Del deletes a Listener.
+func (l *CountListeners) Del(listener *CountListener) {
+	l.ListenerContainer.Del(listener)
+}
+
+// Broadcast calls every listener with the new count.
+func (l *CountListeners) Broadcast(count int64) {
+	l.ListenerContainer.Foreach(func(listener interface{}) {
+		listener.(*CountListener).Handle(count)
+	})
+}
@@ -0,0 +1,10 @@
+package atomic
+
+import "errors"
+
+var (
+	// ErrAlreadyStarted indicates Start is called more than once.
+	ErrAlreadyStarted = errors.New("already started")
+	// ErrNotStarted indicates the SharedCount is not started or closed already.
+	ErrNotStarted = errors.New("not started")
+)
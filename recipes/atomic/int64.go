@@ -0,0 +1,189 @@
+// Package atomic contains recipes of distributed atomic values.
+package atomic
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/locks"
+)
+
+// defaultMaxAttempts is the default number of optimistic attempts.
+const defaultMaxAttempts = 10
+
+// Int64 is an int64 shared by processes, stored in a znode as a decimal
+// integer. The value is zero if the znode does not exist.
+//
+// Operations compare and set the znode with its version optimistically. If
+// they keep failing due to contention, the operations are either given up, or
+// promoted to be done while holding a lock if a lock path is set.
+type Int64 struct {
+	client      *enhanced.Client
+	path        string
+	lockPath    string
+	maxAttempts int
+}
+
+// NewInt64 creates an Int64 stored in p.
+func NewInt64(client *enhanced.Client, p string) *Int64 {
+	return &Int64{
+		client:      client,
+		path:        path.Join("/", p),
+		maxAttempts: defaultMaxAttempts,
+	}
+}
+
+// SetMaxAttempts sets the number of attempts before an operation is given up
+// or promoted, the default value is 10.
+func (i *Int64) SetMaxAttempts(n int) *Int64 {
+	i.maxAttempts = n
+	return i
+}
+
+// SetPromotedLock sets the path of the lock to hold when operations are
+// promoted, operations are given up if it's not set.
+func (i *Int64) SetPromotedLock(p string) *Int64 {
+	i.lockPath = p
+	return i
+}
+
+// Get returns the current value.
+func (i *Int64) Get() (int64, error) {
+	var data, _, err = i.client.Get(i.path)
+	if err == zk.ErrNoNode {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseInt64(data)
+}
+
+// Initialize sets the value if the znode does not exist.
+// The returning value indicates whether the value is set.
+func (i *Int64) Initialize(v int64) (bool, error) {
+	var _, err = i.client.CreateValueWithParents(i.path, formatInt64(v))
+	if err == zk.ErrNodeExists {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Set sets the value regardless of the current one.
+func (i *Int64) Set(v int64) (AtomicValue, error) {
+	return i.modify(func(int64) (int64, bool) {
+		return v, true
+	})
+}
+
+// CompareAndSet sets the value to v if the current value is expected.
+func (i *Int64) CompareAndSet(expected, v int64) (AtomicValue, error) {
+	return i.modify(func(old int64) (int64, bool) {
+		return v, old == expected
+	})
+}
+
+// Add adds delta to the value.
+func (i *Int64) Add(delta int64) (AtomicValue, error) {
+	return i.modify(func(old int64) (int64, bool) {
+		return old + delta, true
+	})
+}
+
+// Increment adds 1 to the value.
+func (i *Int64) Increment() (AtomicValue, error) {
+	return i.Add(1)
+}
+
+// Decrement subtracts 1 from the value.
+func (i *Int64) Decrement() (AtomicValue, error) {
+	return i.Add(-1)
+}
+
+// modify sets the value to the result of fn, the operation is not done if
+// fn returns false.
+func (i *Int64) modify(fn func(old int64) (int64, bool)) (AtomicValue, error) {
+	var result, done, err = i.attempt(fn)
+	if done || err != nil || i.lockPath == "" {
+		return result, err
+	}
+
+	var mutex = locks.NewMutex(i.client, i.lockPath)
+	if err = mutex.Lock(context.Background()); err != nil {
+		return result, err
+	}
+	defer func() {
+		if unlockErr := mutex.Unlock(); unlockErr != nil {
+			i.client.Logger().Error("unlocking promoted lock", "path", i.lockPath, "err", unlockErr)
+		}
+	}()
+	result, _, err = i.attempt(fn)
+	return result, err
+}
+
+// attempt tries to modify the value up to maxAttempts times.
+// The returning value indicates whether it's done, successfully or not.
+func (i *Int64) attempt(fn func(old int64) (int64, bool)) (AtomicValue, bool, error) {
+	var result AtomicValue
+	for n := 0; n < i.maxAttempts; n++ {
+		var done, err = i.tryOnce(&result, fn)
+		if done || err != nil {
+			return result, done, err
+		}
+	}
+	return result, false, nil
+}
+
+// tryOnce compares and sets the value once.
+// The returning value indicates whether it's done, successfully or not.
+func (i *Int64) tryOnce(result *AtomicValue, fn func(old int64) (int64, bool)) (bool, error) {
+	var exists = true
+	var data, stat, err = i.client.Get(i.path)
+	if err == zk.ErrNoNode {
+		exists = false
+	} else if err != nil {
+		return false, err
+	}
+
+	result.Succeeded = false
+	result.PreValue = 0
+	result.PostValue = 0
+	if exists {
+		if result.PreValue, err = parseInt64(data); err != nil {
+			return false, err
+		}
+	}
+	var v, proceed = fn(result.PreValue)
+	if !proceed {
+		return true, nil
+	}
+
+	if exists {
+		_, err = i.client.Set(i.path, formatInt64(v), stat.Version)
+	} else {
+		_, err = i.client.CreateValueWithParents(i.path, formatInt64(v))
+	}
+	switch err {
+	case nil:
+		result.Succeeded = true
+		result.PostValue = v
+		return true, nil
+	case zk.ErrBadVersion, zk.ErrNodeExists, zk.ErrNoNode:
+		// Changed by others in the meantime.
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func formatInt64(v int64) []byte {
+	return []byte(strconv.FormatInt(v, 10))
+}
+
+func parseInt64(data []byte) (int64, error) {
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,53 @@
+package atomic
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestFormatInt64(t *testing.T) {
+	var v, err = parseInt64(formatInt64(-42))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-42), v)
+	_, err = parseInt64([]byte("x"))
+	assert.Error(t, err)
+}
+
+func TestInt64(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		var counter = NewInt64(client, "/counter").SetPromotedLock("/counter-lock")
+
+		var v, err = counter.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), v)
+		initialized, err := counter.Initialize(5)
+		assert.NoError(t, err)
+		assert.True(t, initialized)
+
+		result, err := counter.CompareAndSet(1, 2)
+		assert.NoError(t, err)
+		assert.False(t, result.Succeeded)
+		assert.Equal(t, int64(5), result.PreValue)
+
+		var wg sync.WaitGroup
+		for n := 0; n < 10; n++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var result, err = NewInt64(client, "/counter").SetMaxAttempts(1).
+					SetPromotedLock("/counter-lock").Increment()
+				assert.NoError(t, err)
+				assert.True(t, result.Succeeded)
+			}()
+		}
+		wg.Wait()
+		v, err = counter.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(15), v)
+	})
+}
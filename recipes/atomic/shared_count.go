@@ -0,0 +1,202 @@
+package atomic
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/abool"
+	"github.com/tevino/zoo/enhanced"
+)
+
+// rewatchInterval is the time to wait before watching again after a failure.
+const rewatchInterval = time.Second
+
+// SharedCount is a count shared by processes, stored in a znode as a decimal
+// integer. The znode is watched, CountListeners are notified once the count
+// changes.
+type SharedCount struct {
+	client    *enhanced.Client
+	path      string
+	seed      int64
+	listeners *CountListeners
+	logger    enhanced.Logger
+	ctx       context.Context
+	cancel    context.CancelFunc
+	started   *abool.AtomicBool
+
+	lock  sync.RWMutex
+	value VersionedValue
+}
+
+// NewSharedCount creates a SharedCount stored in p, the znode is created
+// with seed on Start if it does not exist.
+func NewSharedCount(client *enhanced.Client, p string, seed int64) *SharedCount {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var c = &SharedCount{
+		client:    client,
+		path:      path.Join("/", p),
+		seed:      seed,
+		listeners: NewCountListeners(),
+		logger:    enhanced.NopLogger,
+		started:   abool.New(),
+		ctx:       ctx,
+		cancel:    cancel,
+		value:     VersionedValue{Version: -1, Value: seed},
+	}
+	if client != nil {
+		c.logger = client.Logger()
+	}
+	return c
+}
+
+// Start creates the znode if necessary, then starts watching it.
+// The SharedCount is not started if an error is returned, Start can be called
+// again then.
+func (c *SharedCount) Start() error {
+	if !c.started.SetToIf(false, true) {
+		return ErrAlreadyStarted
+	}
+	var _, err = c.client.CreateValueWithParents(c.path, formatInt64(c.seed))
+	if err == zk.ErrNodeExists {
+		err = nil
+	}
+	if err == nil {
+		err = c.refresh(c.ctx)
+	}
+	if err != nil {
+		c.started.UnSet()
+		return err
+	}
+	go c.watch()
+	return nil
+}
+
+// Close stops watching the znode.
+func (c *SharedCount) Close() error {
+	if !c.started.IsSet() {
+		return ErrNotStarted
+	}
+	c.cancel()
+	return nil
+}
+
+// Count returns the current count.
+func (c *SharedCount) Count() int64 {
+	return c.VersionedValue().Value
+}
+
+// VersionedValue returns the current count along with its version.
+func (c *SharedCount) VersionedValue() VersionedValue {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.value
+}
+
+// SetCount sets the count regardless of the current one.
+func (c *SharedCount) SetCount(count int64) error {
+	var stat, err = c.client.Set(c.path, formatInt64(count), -1)
+	if err == nil {
+		c.update(VersionedValue{Version: stat.Version, Value: count})
+	}
+	return err
+}
+
+// TrySetCount sets the count if it's not changed since previous was read.
+// The returning value indicates whether the count is set.
+func (c *SharedCount) TrySetCount(previous VersionedValue, count int64) (bool, error) {
+	var stat, err = c.client.Set(c.path, formatInt64(count), previous.Version)
+	if err == zk.ErrBadVersion || err == zk.ErrNoNode {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	c.update(VersionedValue{Version: stat.Version, Value: count})
+	return true, nil
+}
+
+// AddListener adds a CountListener.
+// Listeners are called with the new count once it changes.
+func (c *SharedCount) AddListener(listener *CountListener) {
+	c.listeners.Add(listener)
+}
+
+// DelListener deletes a CountListener.
+func (c *SharedCount) DelListener(listener *CountListener) {
+	c.listeners.Del(listener)
+}
+
+func (c *SharedCount) watch() {
+	for c.ctx.Err() == nil {
+		if err := c.watchOnce(); err != nil && c.ctx.Err() == nil {
+			c.logger.Warn("watching shared count", "path", c.path, "err", err)
+			select {
+			case <-time.After(rewatchInterval):
+			case <-c.ctx.Done():
+			}
+		}
+	}
+}
+
+// watchOnce refreshes the count then blocks until the znode changes.
+func (c *SharedCount) watchOnce() error {
+	var ctx, cancel = context.WithCancel(c.ctx)
+	defer cancel()
+	var result = make(chan enhanced.ExistResult, 1)
+	var changed = make(chan struct{})
+	c.client.WatchExistCtx(ctx, c.path, func(r enhanced.ExistResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	if r.Err != nil {
+		return r.Err
+	}
+	if r.Exist {
+		if err := c.refresh(ctx); err != nil {
+			return err
+		}
+	}
+	select {
+	case <-changed:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// refresh reads the count from the znode.
+func (c *SharedCount) refresh(ctx context.Context) error {
+	var data, stat, err = c.client.GetCtx(ctx, c.path)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	count, err := parseInt64(data)
+	if err != nil {
+		return err
+	}
+	c.update(VersionedValue{Version: stat.Version, Value: count})
+	return nil
+}
+
+// update sets the count unless it's older than the current one, listeners
+// are notified if the count changes.
+func (c *SharedCount) update(v VersionedValue) {
+	c.lock.Lock()
+	if v.Version <= c.value.Version {
+		c.lock.Unlock()
+		return
+	}
+	var changed = v.Value != c.value.Value
+	c.value = v
+	c.lock.Unlock()
+	if changed {
+		c.listeners.Broadcast(v.Value)
+	}
+}
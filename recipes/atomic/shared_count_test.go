@@ -0,0 +1,66 @@
+package atomic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestSharedCount(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var a = NewSharedCount(env.NewClientTimeout(time.Second*2), "/count", 1)
+		var b = NewSharedCount(env.NewClientTimeout(time.Second*2), "/count", 2)
+		assert.NoError(t, a.Start())
+		assert.NoError(t, b.Start())
+		assert.Equal(t, int64(1), b.Count())
+
+		var changes = make(chan int64, 1)
+		b.AddListener(NewCountListener(func(count int64) {
+			changes <- count
+		}))
+
+		var previous = a.VersionedValue()
+		ok, err := a.TrySetCount(previous, 3)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = a.TrySetCount(previous, 4)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		select {
+		case count := <-changes:
+			assert.Equal(t, int64(3), count)
+		case <-time.After(time.Second * 5):
+			t.Fatal("Waiting for count change timed out")
+		}
+		assert.NoError(t, a.Close())
+		assert.NoError(t, b.Close())
+	})
+}
+
+func TestNewSharedCountWithoutClient(t *testing.T) {
+	var c = NewSharedCount(nil, "/count", 1)
+	assert.Equal(t, int64(1), c.Count())
+	assert.Equal(t, ErrNotStarted, c.Close())
+}
+
+func TestSharedCountStartFailure(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		_, err := client.CreateValue("/count", []byte("not a number"))
+		assert.NoError(t, err)
+
+		var c = NewSharedCount(client, "/count", 1)
+		assert.Error(t, c.Start())
+		assert.NotEqual(t, ErrAlreadyStarted, c.Start())
+		assert.Equal(t, ErrNotStarted, c.Close())
+
+		_, err = client.Set("/count", formatInt64(2), -1)
+		assert.NoError(t, err)
+		assert.NoError(t, c.Start())
+		assert.Equal(t, int64(2), c.Count())
+		assert.NoError(t, c.Close())
+	})
+}
@@ -0,0 +1,9 @@
+package atomic
+
+// VersionedValue is a value along with the version of its znode.
+type VersionedValue struct {
+	// Version is the version of the znode, it's -1 if the znode does not exist.
+	Version int32
+	// Value is the value stored in the znode.
+	Value int64
+}
@@ -0,0 +1,89 @@
+// Package barrier contains recipes of distributed barriers.
+package barrier
+
+import (
+	"context"
+	"path"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+)
+
+// Barrier blocks processes until a condition is met, the barrier is up as
+// long as its node exists.
+type Barrier struct {
+	client *enhanced.Client
+	path   string
+}
+
+// NewBarrier creates a Barrier on p.
+func NewBarrier(client *enhanced.Client, p string) *Barrier {
+	return &Barrier{
+		client: client,
+		path:   path.Join("/", p),
+	}
+}
+
+// Set sets the barrier up, it's not an error if it's already up.
+// The barrier node is persistent regardless of the flags of the client.
+func (b *Barrier) Set() error {
+	var _, err = b.client.CreateWithParents(b.path, enhanced.WithMode(enhanced.ModePersistent))
+	if err == zk.ErrNodeExists {
+		return nil
+	}
+	return err
+}
+
+// Remove removes the barrier, it's not an error if it's not up.
+func (b *Barrier) Remove() error {
+	var err = b.client.Delete(b.path, -1)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+// Wait blocks until the barrier is removed or ctx is done.
+// The error of ctx is returned if ctx is done first.
+func (b *Barrier) Wait(ctx context.Context) error {
+	for {
+		var removed, err = b.checkRemoved(ctx)
+		if err != nil || removed {
+			return err
+		}
+	}
+}
+
+// checkRemoved returns true if the barrier is not up, otherwise it blocks
+// until the barrier node changes.
+func (b *Barrier) checkRemoved(ctx context.Context) (bool, error) {
+	var watchCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	var exist, changed, err = watchExist(watchCtx, b.client, b.path)
+	if err != nil {
+		return false, err
+	}
+	if !exist {
+		return true, nil
+	}
+	select {
+	case <-changed:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// watchExist returns whether p exists, changed is closed once p is created,
+// deleted or changed. The watch is dropped once ctx is done.
+func watchExist(ctx context.Context, client *enhanced.Client, p string) (bool, <-chan struct{}, error) {
+	var result = make(chan enhanced.ExistResult, 1)
+	var changed = make(chan struct{})
+	client.WatchExistCtx(ctx, p, func(r enhanced.ExistResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	return r.Exist, changed, r.Err
+}
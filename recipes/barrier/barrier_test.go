@@ -0,0 +1,104 @@
+package barrier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func TestBarrier(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var b = NewBarrier(env.NewClientTimeout(time.Second*2), "/barrier")
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.NoError(t, b.Set())
+		assert.NoError(t, b.Set())
+
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, b.Wait(ctx))
+
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			b.Remove()
+		}()
+		assert.NoError(t, b.Wait(context.Background()))
+		assert.NoError(t, b.Remove())
+	})
+}
+
+func TestDoubleBarrier(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		const members = 3
+		var errs = make(chan error, members*2)
+		for i := 0; i < members; i++ {
+			var b = NewDoubleBarrier(env.NewClientTimeout(time.Second*2), "/double", members)
+			go func() {
+				var ctx, cancel = context.WithTimeout(context.Background(), time.Second*10)
+				defer cancel()
+				errs <- b.Enter(ctx)
+				errs <- b.Leave(ctx)
+			}()
+		}
+		for i := 0; i < members*2; i++ {
+			assert.NoError(t, <-errs)
+		}
+		var children, _, err = env.Client().GetChildren("/double")
+		assert.NoError(t, err)
+		assert.Empty(t, children)
+	})
+}
+
+func TestDoubleBarrierCancel(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var b = NewDoubleBarrier(env.NewClientTimeout(time.Second*2), "/double", 2).SetID("a")
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, b.Enter(ctx))
+		env.AssertNoZNode("/double/a")
+	})
+}
+
+func TestBarrierIgnoresClientFlags(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		client.SetFlags(zk.FlagEphemeral)
+		assert.NoError(t, NewBarrier(client, "/barrier").Set())
+		var _, stat, err = env.Client().Get("/barrier")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), stat.EphemeralOwner)
+	})
+}
+
+func TestDoubleBarrierReadyOutlivesMembers(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		const members = 2
+		var clients []*enhanced.Client
+		var errs = make(chan error, members)
+		for i := 0; i < members; i++ {
+			var client = env.NewClientTimeout(time.Second * 2)
+			clients = append(clients, client)
+			var b = NewDoubleBarrier(client, "/double", members)
+			go func() {
+				var ctx, cancel = context.WithTimeout(context.Background(), time.Second*10)
+				defer cancel()
+				errs <- b.Enter(ctx)
+			}()
+		}
+		for i := 0; i < members; i++ {
+			assert.NoError(t, <-errs)
+		}
+
+		// The ready node stays after members crash without leaving.
+		for _, client := range clients {
+			client.Close()
+		}
+		var children, _, err = env.Client().GetChildren("/double")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{readyNodeName}, children)
+	})
+}
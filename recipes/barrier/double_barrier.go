@@ -0,0 +1,212 @@
+package barrier
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// readyNodeName is the name of the node created once all members entered.
+const readyNodeName = "ready"
+
+// DoubleBarrier enables a number of members to enter and leave together.
+//
+// Every member creates an ephemeral node named by its id under the path on
+// entering, the last one to enter creates the persistent ready node which
+// releases all members. On leaving, members wait until all of them deleted
+// their nodes, the last one to leave deletes the ready node.
+//
+// NOTE: The ready node is left behind if members crash before leaving, it
+// must be deleted before the barrier is used again.
+type DoubleBarrier struct {
+	client      *enhanced.Client
+	path        string
+	readyPath   string
+	memberCount int
+	id          string
+	ourPath     string
+}
+
+// NewDoubleBarrier creates a DoubleBarrier on p for memberCount members, the
+// member id is random.
+func NewDoubleBarrier(client *enhanced.Client, p string, memberCount int) *DoubleBarrier {
+	var b = &DoubleBarrier{
+		client:      client,
+		path:        path.Join("/", p),
+		memberCount: memberCount,
+	}
+	b.readyPath = path.Join(b.path, readyNodeName)
+	return b.SetID(znodes.NewToken())
+}
+
+// SetID sets the member id, which must be unique among members.
+// It should not be called once the DoubleBarrier is used.
+func (b *DoubleBarrier) SetID(id string) *DoubleBarrier {
+	b.id = id
+	b.ourPath = path.Join(b.path, id)
+	return b
+}
+
+// ID returns the member id.
+func (b *DoubleBarrier) ID() string {
+	return b.id
+}
+
+// Enter blocks until all members entered or ctx is done.
+// Our member node is deleted if an error is returned.
+func (b *DoubleBarrier) Enter(ctx context.Context) error {
+	var err = b.enter(ctx)
+	if err != nil {
+		b.cleanup()
+	}
+	return err
+}
+
+// Leave blocks until all members left or ctx is done.
+// Our member node is deleted even if an error is returned.
+func (b *DoubleBarrier) Leave(ctx context.Context) error {
+	var err = b.leave(ctx)
+	if err != nil {
+		b.cleanup()
+	}
+	return err
+}
+
+func (b *DoubleBarrier) enter(ctx context.Context) error {
+	if err := znodes.EnsurePath(b.client, b.path); err != nil {
+		return err
+	}
+	if _, err := b.client.Create(b.ourPath, enhanced.WithMode(enhanced.ModeEphemeral)); err != nil {
+		return err
+	}
+	for {
+		var entered, err = b.checkEntered(ctx)
+		if err != nil || entered {
+			return err
+		}
+	}
+}
+
+// checkEntered returns true if all members entered, otherwise it blocks until
+// the ready node or members change.
+func (b *DoubleBarrier) checkEntered(ctx context.Context) (bool, error) {
+	var watchCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	var ready, readyChanged, err = watchExist(watchCtx, b.client, b.readyPath)
+	if err != nil || ready {
+		return ready, err
+	}
+	members, membersChanged, err := b.watchMembers(watchCtx)
+	if err != nil {
+		return false, err
+	}
+	if len(members) >= b.memberCount {
+		if _, err = b.client.Create(b.readyPath, enhanced.WithMode(enhanced.ModePersistent)); err != nil && err != zk.ErrNodeExists {
+			return false, err
+		}
+		return true, nil
+	}
+	select {
+	case <-readyChanged:
+		// The ready node is created, it's deleted only by the last member
+		// to leave, which waits for our node to be deleted first.
+		return false, nil
+	case <-membersChanged:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func (b *DoubleBarrier) leave(ctx context.Context) error {
+	for {
+		var left, err = b.checkLeft(ctx)
+		if err != nil || left {
+			return err
+		}
+	}
+}
+
+// checkLeft returns true if all members left, otherwise it blocks until the
+// member it waits for leaves.
+//
+// The lowest member waits for the highest one, others delete their nodes
+// then wait for the lowest one. So the lowest member leaves last, and it
+// deletes the ready node before its own node to close the barrier.
+func (b *DoubleBarrier) checkLeft(ctx context.Context) (bool, error) {
+	var members, _, err = b.client.GetChildrenCtx(ctx, b.path)
+	if err != nil {
+		return false, err
+	}
+	members = withoutReady(members)
+	if len(members) == 0 {
+		return true, nil
+	}
+	sort.Strings(members)
+	var lowest, highest = members[0], members[len(members)-1]
+	var ours = znodes.IndexOf(members, b.id) >= 0
+
+	var waitFor string
+	switch {
+	case len(members) == 1 && ours:
+		if err = b.client.Delete(b.readyPath, -1); err != nil && err != zk.ErrNoNode {
+			return false, err
+		}
+		return true, znodes.Delete(b.client, b.ourPath)
+	case lowest == b.id:
+		waitFor = highest
+	default:
+		if ours {
+			if err = znodes.Delete(b.client, b.ourPath); err != nil {
+				return false, err
+			}
+		}
+		waitFor = lowest
+	}
+
+	var watchCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	exist, changed, err := watchExist(watchCtx, b.client, path.Join(b.path, waitFor))
+	if err != nil || !exist {
+		return false, err
+	}
+	select {
+	case <-changed:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// watchMembers returns current members, changed is closed once they change.
+func (b *DoubleBarrier) watchMembers(ctx context.Context) ([]string, <-chan struct{}, error) {
+	var result = make(chan enhanced.ChildrenResult, 1)
+	var changed = make(chan struct{})
+	b.client.WatchChildrenCtx(ctx, b.path, func(r enhanced.ChildrenResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	return withoutReady(r.Children), changed, r.Err
+}
+
+func (b *DoubleBarrier) cleanup() {
+	if err := znodes.Delete(b.client, b.ourPath); err != nil {
+		b.client.Logger().Error("deleting double barrier member", "path", b.ourPath, "err", err)
+	}
+}
+
+func withoutReady(children []string) []string {
+	var members = make([]string, 0, len(children))
+	for _, child := range children {
+		if child != readyNodeName {
+			members = append(members, child)
+		}
+	}
+	return members
+}
@@ -0,0 +1,420 @@
+// Package children contains a cache of the direct children of a ZNode.
+package children
+
+import (
+	"context"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/abool"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/caches/tree"
+)
+
+// rewatchInterval is the time to wait before watching again after a failure.
+const rewatchInterval = time.Second
+
+// Cache keeps the direct children of a ZNode current, optionally with their
+// data. Neither the data of the ZNode nor its grandchildren are watched.
+//
+// The ZNode does not have to exist, it's watched for creation then.
+//
+// NOTE: Changes happened in quick succession might be coalesced into one.
+type Cache struct {
+	client        *enhanced.Client
+	path          string
+	cacheData     bool
+	mode          StartMode
+	listeners     *EventListeners
+	stateListener *enhanced.StateChangeListener
+	logger        enhanced.Logger
+	started       *abool.AtomicBool
+	ctx           context.Context
+	cancel        context.CancelFunc
+	// initialized is closed once children existing at the start are loaded.
+	initialized chan struct{}
+
+	lock     sync.RWMutex
+	children map[string]*child
+	// pending contains children existing at the start which are not loaded
+	// yet, it's nil before they are listed.
+	pending       map[string]struct{}
+	isInitialized bool
+}
+
+// child is a cached child.
+type child struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// data is nil until the child is loaded.
+	data *tree.ChildData
+}
+
+// NewCache creates a Cache of the children of p.
+func NewCache(client *enhanced.Client, p string) *Cache {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var cache = &Cache{
+		client:      client,
+		path:        path.Join("/", p),
+		cacheData:   true,
+		listeners:   NewEventListeners(),
+		logger:      enhanced.NopLogger,
+		started:     abool.New(),
+		ctx:         ctx,
+		cancel:      cancel,
+		initialized: make(chan struct{}),
+		children:    make(map[string]*child),
+	}
+	if client != nil {
+		cache.logger = client.Logger()
+	}
+	cache.stateListener = enhanced.NewStateChangeListener(cache.handleStateChange)
+	return cache
+}
+
+// SetCacheData sets whether or not to cache data of children, default true.
+// NOTE: Events contain data of children regardless.
+func (c *Cache) SetCacheData(cacheData bool) *Cache {
+	c.cacheData = cacheData
+	return c
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (c *Cache) SetLogger(l enhanced.Logger) *Cache {
+	c.logger = l
+	return c
+}
+
+// Start starts the cache in given mode.
+func (c *Cache) Start(mode StartMode) error {
+	return c.StartCtx(context.Background(), mode)
+}
+
+// StartCtx is Start with a context, which is used to wait for the initial
+// children to be loaded in StartModeBuildInitial.
+// The cache is stopped if ctx is done before that.
+func (c *Cache) StartCtx(ctx context.Context, mode StartMode) error {
+	if !c.started.SetToIf(false, true) {
+		return ErrAlreadyStarted
+	}
+	c.mode = mode
+	c.client.AddStateChangeListener(c.stateListener)
+	go c.watch()
+	if mode != StartModeBuildInitial {
+		return nil
+	}
+	if err := c.WaitInitialized(ctx); err != nil {
+		c.Stop()
+		return err
+	}
+	return nil
+}
+
+// WaitInitialized blocks until children existing at the start are loaded or
+// ctx is done. The error of ctx is returned if ctx is done first.
+func (c *Cache) WaitInitialized(ctx context.Context) error {
+	select {
+	case <-c.initialized:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops the cache.
+func (c *Cache) Stop() {
+	if c.started.IsSet() {
+		c.client.DelStateChangeListener(c.stateListener)
+	}
+	c.cancel()
+}
+
+// CurrentData returns loaded children sorted by path.
+func (c *Cache) CurrentData() []*tree.ChildData {
+	c.lock.RLock()
+	var result = make([]*tree.ChildData, 0, len(c.children))
+	for _, ch := range c.children {
+		if ch.data != nil {
+			result = append(result, ch.data)
+		}
+	}
+	c.lock.RUnlock()
+	sort.Sort(byPath(result))
+	return result
+}
+
+// CurrentDataOf returns the child at given full path.
+// nil is returned if the child is not loaded.
+func (c *Cache) CurrentDataOf(fullPath string) *tree.ChildData {
+	if path.Dir(fullPath) != c.path {
+		return nil
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if ch, ok := c.children[path.Base(fullPath)]; ok {
+		return ch.data
+	}
+	return nil
+}
+
+// AddEventListener adds an EventListener.
+func (c *Cache) AddEventListener(l *EventListener) {
+	c.listeners.Add(l)
+}
+
+// DelEventListener deletes the first occurrence of l.
+func (c *Cache) DelEventListener(l *EventListener) {
+	c.listeners.Del(l)
+}
+
+func (c *Cache) handleStateChange(s enhanced.ConnState) {
+	switch s {
+	case enhanced.ConnStateSuspended:
+		c.publish(Event{Type: EventConnSuspended})
+	case enhanced.ConnStateLost:
+		c.publish(Event{Type: EventConnLost})
+	case enhanced.ConnStateReconnected:
+		// Watches are gone if the session expired.
+		c.rewatchChildren()
+		c.publish(Event{Type: EventConnReconnected})
+	}
+}
+
+func (c *Cache) watch() {
+	for c.ctx.Err() == nil {
+		if err := c.watchOnce(); err != nil && c.ctx.Err() == nil {
+			c.logger.Warn("watching children", "path", c.path, "err", err)
+			select {
+			case <-time.After(rewatchInterval):
+			case <-c.ctx.Done():
+			}
+		}
+	}
+}
+
+// watchOnce lists children then blocks until they change, the ZNode is
+// watched for creation if it does not exist.
+func (c *Cache) watchOnce() error {
+	var ctx, cancel = context.WithCancel(c.ctx)
+	defer cancel()
+	var result = make(chan enhanced.ChildrenResult, 1)
+	var changed = make(chan struct{})
+	c.client.WatchChildrenCtx(ctx, c.path, func(r enhanced.ChildrenResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	if r.Err == zk.ErrNoNode {
+		c.applyChildren(nil)
+		return c.waitForCreation(ctx)
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+	c.applyChildren(r.Children)
+	select {
+	case <-changed:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// waitForCreation blocks until the ZNode exists.
+func (c *Cache) waitForCreation(ctx context.Context) error {
+	var result = make(chan enhanced.ExistResult, 1)
+	var changed = make(chan struct{})
+	c.client.WatchExistCtx(ctx, c.path, func(r enhanced.ExistResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	if r.Err != nil || r.Exist {
+		return r.Err
+	}
+	select {
+	case <-changed:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// applyChildren watches new children and removes children which are gone.
+func (c *Cache) applyChildren(names []string) {
+	var current = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		current[name] = struct{}{}
+	}
+
+	c.lock.Lock()
+	var added []string
+	for _, name := range names {
+		if _, ok := c.children[name]; !ok {
+			var ctx, cancel = context.WithCancel(c.ctx)
+			c.children[name] = &child{ctx: ctx, cancel: cancel}
+			added = append(added, name)
+		}
+	}
+	var removed []string
+	for name := range c.children {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	var initialListing = c.pending == nil
+	if initialListing {
+		c.pending = make(map[string]struct{}, len(added))
+		for _, name := range added {
+			c.pending[name] = struct{}{}
+		}
+	}
+	c.lock.Unlock()
+
+	for _, name := range removed {
+		c.removeChild(name)
+	}
+	for _, name := range added {
+		c.watchChild(name)
+	}
+	if initialListing {
+		c.checkInitialized()
+	}
+}
+
+// watchChild watches the data of the child named name.
+func (c *Cache) watchChild(name string) {
+	c.lock.RLock()
+	var ch, ok = c.children[name]
+	c.lock.RUnlock()
+	if !ok {
+		return
+	}
+	var ctx = ch.ctx
+	var p = path.Join(c.path, name)
+	c.client.WatchDataCtx(ctx, p, func(r enhanced.DataResult) {
+		switch r.Err {
+		case nil:
+			c.updateChild(name, tree.NewChildData(p, r.Stat, r.Data))
+		case zk.ErrNoNode:
+			c.removeChild(name)
+		default:
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Warn("watching child", "path", p, "err", r.Err)
+			go func() {
+				select {
+				case <-time.After(rewatchInterval):
+					c.watchChild(name)
+				case <-ctx.Done():
+				}
+			}()
+		}
+	}, func(evt zk.Event) {
+		switch evt.Type {
+		case zk.EventNotWatching:
+			// Watched again once reconnected.
+		case zk.EventNodeDeleted:
+			c.removeChild(name)
+		default:
+			c.watchChild(name)
+		}
+	})
+}
+
+// rewatchChildren drops watches on all children then watches them again.
+func (c *Cache) rewatchChildren() {
+	c.lock.Lock()
+	var names = make([]string, 0, len(c.children))
+	for name, ch := range c.children {
+		ch.cancel()
+		ch.ctx, ch.cancel = context.WithCancel(c.ctx)
+		names = append(names, name)
+	}
+	c.lock.Unlock()
+	for _, name := range names {
+		c.watchChild(name)
+	}
+}
+
+// updateChild sets the data of the child and publishes the change if any.
+func (c *Cache) updateChild(name string, data *tree.ChildData) {
+	var stored = data
+	if !c.cacheData {
+		stored = tree.NewChildData(data.Path(), data.Stat(), nil)
+	}
+	c.lock.Lock()
+	var ch, ok = c.children[name]
+	if !ok {
+		c.lock.Unlock()
+		return
+	}
+	var old = ch.data
+	ch.data = stored
+	var _, initial = c.pending[name]
+	delete(c.pending, name)
+	c.lock.Unlock()
+
+	switch {
+	case old == nil:
+		if !initial || c.mode == StartModeNormal {
+			c.publish(Event{Type: EventChildAdded, Data: data})
+		}
+		c.checkInitialized()
+	case old.Stat().Mzxid != data.Stat().Mzxid:
+		c.publish(Event{Type: EventChildUpdated, Data: data})
+	}
+}
+
+// removeChild removes the child and publishes the removal if it's loaded.
+func (c *Cache) removeChild(name string) {
+	c.lock.Lock()
+	var ch, ok = c.children[name]
+	if ok {
+		ch.cancel()
+		delete(c.children, name)
+		delete(c.pending, name)
+	}
+	c.lock.Unlock()
+	if !ok {
+		return
+	}
+	if ch.data != nil {
+		c.publish(Event{Type: EventChildRemoved, Data: ch.data})
+	}
+	c.checkInitialized()
+}
+
+// checkInitialized marks the cache initialized once children existing at
+// the start are loaded.
+func (c *Cache) checkInitialized() {
+	c.lock.Lock()
+	if c.isInitialized || c.pending == nil || len(c.pending) > 0 {
+		c.lock.Unlock()
+		return
+	}
+	c.isInitialized = true
+	c.lock.Unlock()
+
+	close(c.initialized)
+	if c.mode != StartModeBuildInitial {
+		c.publish(Event{Type: EventInitialized})
+	}
+}
+
+func (c *Cache) publish(e Event) {
+	c.logger.Debug("publishing children cache event", "event", e)
+	c.listeners.Broadcast(e)
+}
+
+// byPath sorts ChildData by path.
+type byPath []*tree.ChildData
+
+func (s byPath) Len() int           { return len(s) }
+func (s byPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byPath) Less(i, j int) bool { return s[i].Path() < s[j].Path() }
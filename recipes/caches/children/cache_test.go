@@ -0,0 +1,82 @@
+package children
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func expectEvent(t *testing.T, events <-chan Event, tp EventType, p string) {
+	select {
+	case e := <-events:
+		assert.Equal(t, tp, e.Type)
+		if p != "" {
+			assert.Equal(t, p, e.Data.Path())
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("Waiting for %s timed out", tp)
+	}
+}
+
+func TestBuildInitial(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		_, err := client.CreateValueWithParents("/parent/a", []byte("a"))
+		assert.NoError(t, err)
+		_, err = client.CreateValue("/parent/a/grandchild", nil)
+		assert.NoError(t, err)
+
+		var cache = NewCache(client, "/parent")
+		var events = make(chan Event, 10)
+		cache.AddEventListener(NewEventListener(func(e Event) {
+			events <- e
+		}))
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		assert.NoError(t, cache.StartCtx(ctx, StartModeBuildInitial))
+		var current = cache.CurrentData()
+		assert.Len(t, current, 1)
+		assert.Equal(t, "a", string(current[0].Data()))
+
+		_, err = client.CreateValue("/parent/b", []byte("b"))
+		assert.NoError(t, err)
+		expectEvent(t, events, EventChildAdded, "/parent/b")
+		_, err = client.Set("/parent/a", []byte("a2"), -1)
+		assert.NoError(t, err)
+		expectEvent(t, events, EventChildUpdated, "/parent/a")
+		assert.Equal(t, "a2", string(cache.CurrentDataOf("/parent/a").Data()))
+		assert.NoError(t, client.Delete("/parent/b", -1))
+		expectEvent(t, events, EventChildRemoved, "/parent/b")
+		cache.Stop()
+	})
+}
+
+func TestInitialEvents(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		_, err := client.CreateWithParents("/parent/a")
+		assert.NoError(t, err)
+
+		var normal = NewCache(client, "/parent")
+		var events = make(chan Event, 10)
+		normal.AddEventListener(NewEventListener(func(e Event) {
+			events <- e
+		}))
+		assert.NoError(t, normal.Start(StartModeNormal))
+		expectEvent(t, events, EventChildAdded, "/parent/a")
+		expectEvent(t, events, EventInitialized, "")
+		normal.Stop()
+
+		var suppressed = NewCache(client, "/parent").SetCacheData(false)
+		suppressed.AddEventListener(NewEventListener(func(e Event) {
+			events <- e
+		}))
+		assert.NoError(t, suppressed.Start(StartModeSuppressInitial))
+		expectEvent(t, events, EventInitialized, "")
+		assert.Nil(t, suppressed.CurrentDataOf("/parent/a").Data())
+		suppressed.Stop()
+	})
+}
@@ -0,0 +1,8 @@
+package children
+
+import "errors"
+
+var (
+	// ErrAlreadyStarted indicates Start is called more than once.
+	ErrAlreadyStarted = errors.New("already started")
+)
@@ -0,0 +1,69 @@
+package children
+
+import (
+	"fmt"
+
+	"github.com/tevino/zoo/recipes/caches/tree"
+)
+
+// EventType represents the type of change to the children.
+type EventType int
+
+const (
+	// EventChildAdded indicates a child was added.
+	EventChildAdded EventType = iota
+	// EventChildUpdated indicates the data of a child was changed.
+	EventChildUpdated
+	// EventChildRemoved indicates a child was removed.
+	EventChildRemoved
+
+	// EventConnSuspended is published when the connection has changed to SUSPENDED.
+	EventConnSuspended
+	// EventConnReconnected is published when the connection has changed to RECONNECTED.
+	EventConnReconnected
+	// EventConnLost is published when the connection has changed to LOST.
+	EventConnLost
+	// EventInitialized is published after children existing at the start are loaded.
+	EventInitialized
+)
+
+// String returns the string representation of EventType.
+// "Unknown" is returned when event type is unknown
+func (et EventType) String() string {
+	switch et {
+	case EventChildAdded:
+		return "ChildAdded"
+	case EventChildUpdated:
+		return "ChildUpdated"
+	case EventChildRemoved:
+		return "ChildRemoved"
+	case EventConnSuspended:
+		return "ConnSuspended"
+	case EventConnReconnected:
+		return "ConnReconnected"
+	case EventConnLost:
+		return "ConnLost"
+	case EventInitialized:
+		return "Initialized"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event represents a change to the children.
+type Event struct {
+	Type EventType
+	// Data is the data of the child, it's nil for events of other types.
+	Data *tree.ChildData
+}
+
+// String returns the string representation of Event
+func (e Event) String() string {
+	var path string
+	var data []byte
+	if e.Data != nil {
+		path = e.Data.Path()
+		data = e.Data.Data()
+	}
+	return fmt.Sprintf("Event{%s %s '%s'}", e.Type, path, data)
+}
@@ -0,0 +1,18 @@
+package children
+
+// StartMode represents how a Cache is started.
+type StartMode int
+
+const (
+	// StartModeNormal starts the cache in the background, children existing
+	// at the start are published as EventChildAdded, then EventInitialized
+	// is published.
+	StartModeNormal StartMode = iota
+	// StartModeSuppressInitial starts the cache in the background, no event
+	// is published for children existing at the start, EventInitialized is
+	// published once they are loaded.
+	StartModeSuppressInitial
+	// StartModeBuildInitial loads children existing at the start before
+	// Start returns, no event is published for them.
+	StartModeBuildInitial
+)
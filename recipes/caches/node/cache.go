@@ -0,0 +1,203 @@
+// Package node contains a cache of a single ZNode.
+package node
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/abool"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/caches/tree"
+)
+
+// rewatchInterval is the time to wait before watching again after a failure.
+const rewatchInterval = time.Second
+
+// Cache keeps the data and stat of a single ZNode current.
+//
+// The node is watched for existence, so its creation, deletion and data
+// changes are all caught. The node does not have to exist.
+//
+// NOTE: Changes happened in quick succession might be coalesced into one.
+type Cache struct {
+	client    *enhanced.Client
+	path      string
+	listeners *EventListeners
+	logger    enhanced.Logger
+	started   *abool.AtomicBool
+	ctx       context.Context
+	cancel    context.CancelFunc
+	// initialized is closed once the node is loaded for the first time.
+	initialized chan struct{}
+	initOnce    sync.Once
+
+	lock    sync.RWMutex
+	current *tree.ChildData
+}
+
+// NewCache creates a Cache of the node at p.
+func NewCache(client *enhanced.Client, p string) *Cache {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var cache = &Cache{
+		client:      client,
+		path:        path.Join("/", p),
+		listeners:   NewEventListeners(),
+		logger:      enhanced.NopLogger,
+		started:     abool.New(),
+		ctx:         ctx,
+		cancel:      cancel,
+		initialized: make(chan struct{}),
+	}
+	if client != nil {
+		cache.logger = client.Logger()
+	}
+	return cache
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (c *Cache) SetLogger(l enhanced.Logger) *Cache {
+	c.logger = l
+	return c
+}
+
+// Start starts watching the node.
+func (c *Cache) Start() error {
+	if !c.started.SetToIf(false, true) {
+		return ErrAlreadyStarted
+	}
+	go c.watch()
+	return nil
+}
+
+// StartAndWait starts the cache then waits until it's initialized.
+// ErrWaitInitTimeout is returned if it's not initialized within timeout, the
+// cache is stopped in that case.
+func (c *Cache) StartAndWait(timeout time.Duration) error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := c.WaitInitialized(ctx); err != nil {
+		c.Stop()
+		return ErrWaitInitTimeout
+	}
+	return nil
+}
+
+// WaitInitialized blocks until the node is loaded for the first time or ctx
+// is done. The error of ctx is returned if ctx is done first.
+func (c *Cache) WaitInitialized(ctx context.Context) error {
+	select {
+	case <-c.initialized:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops watching the node.
+func (c *Cache) Stop() {
+	c.cancel()
+}
+
+// Current returns the current data of the node.
+// nil is returned if the node does not exist or the cache is not initialized.
+func (c *Cache) Current() *tree.ChildData {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.current
+}
+
+// AddEventListener adds an EventListener.
+func (c *Cache) AddEventListener(l *EventListener) {
+	c.listeners.Add(l)
+}
+
+// DelEventListener deletes the first occurrence of l.
+func (c *Cache) DelEventListener(l *EventListener) {
+	c.listeners.Del(l)
+}
+
+func (c *Cache) watch() {
+	for c.ctx.Err() == nil {
+		if err := c.watchOnce(); err != nil && c.ctx.Err() == nil {
+			c.logger.Warn("watching node", "path", c.path, "err", err)
+			select {
+			case <-time.After(rewatchInterval):
+			case <-c.ctx.Done():
+			}
+		}
+	}
+}
+
+// watchOnce refreshes the node then blocks until it changes.
+func (c *Cache) watchOnce() error {
+	var ctx, cancel = context.WithCancel(c.ctx)
+	defer cancel()
+	var result = make(chan enhanced.ExistResult, 1)
+	var changed = make(chan struct{})
+	c.client.WatchExistCtx(ctx, c.path, func(r enhanced.ExistResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	if r.Err != nil {
+		return r.Err
+	}
+	if err := c.refresh(ctx, r.Exist); err != nil {
+		return err
+	}
+	select {
+	case <-changed:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// refresh loads the node, the watch on its existence fires if it's created
+// or deleted in the meantime.
+func (c *Cache) refresh(ctx context.Context, exist bool) error {
+	var data *tree.ChildData
+	if exist {
+		var value, stat, err = c.client.GetCtx(ctx, c.path)
+		if err != nil && err != zk.ErrNoNode {
+			return err
+		}
+		if err == nil {
+			data = tree.NewChildData(c.path, stat, value)
+		}
+	}
+	c.update(data)
+	c.initOnce.Do(func() {
+		close(c.initialized)
+	})
+	return nil
+}
+
+// update sets the current data and publishes the change if any.
+func (c *Cache) update(data *tree.ChildData) {
+	c.lock.Lock()
+	var old = c.current
+	c.current = data
+	c.lock.Unlock()
+
+	switch {
+	case old == nil && data != nil:
+		c.publish(Event{Type: EventNodeCreated, Data: data})
+	case old != nil && data == nil:
+		c.publish(Event{Type: EventNodeDeleted, Data: old})
+	case old != nil && old.Stat().Mzxid != data.Stat().Mzxid:
+		c.publish(Event{Type: EventNodeUpdated, Data: data})
+	}
+}
+
+func (c *Cache) publish(e Event) {
+	c.logger.Debug("publishing node cache event", "event", e)
+	c.listeners.Broadcast(e)
+}
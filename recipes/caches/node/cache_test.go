@@ -0,0 +1,64 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestCache(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		var cache = NewCache(client, "/node")
+		var events = make(chan Event, 3)
+		cache.AddEventListener(NewEventListener(func(e Event) {
+			events <- e
+		}))
+		assert.NoError(t, cache.StartAndWait(time.Second*5))
+		assert.Nil(t, cache.Current())
+
+		var expect = func(tp EventType, data string) {
+			select {
+			case e := <-events:
+				assert.Equal(t, tp, e.Type)
+				assert.Equal(t, data, string(e.Data.Data()))
+			case <-time.After(time.Second * 5):
+				t.Fatalf("Waiting for %s timed out", tp)
+			}
+		}
+		_, err := client.CreateValue("/node", []byte("a"))
+		assert.NoError(t, err)
+		expect(EventNodeCreated, "a")
+		_, err = client.Set("/node", []byte("b"), -1)
+		assert.NoError(t, err)
+		expect(EventNodeUpdated, "b")
+		assert.Equal(t, "b", string(cache.Current().Data()))
+		assert.NoError(t, client.Delete("/node", -1))
+		expect(EventNodeDeleted, "b")
+		assert.Nil(t, cache.Current())
+		cache.Stop()
+	})
+}
+
+func TestStartAndWaitTimeout(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		var cache = NewCache(client, "/node")
+		var events = make(chan Event, 1)
+		cache.AddEventListener(NewEventListener(func(e Event) {
+			events <- e
+		}))
+		assert.Equal(t, ErrWaitInitTimeout, cache.StartAndWait(0))
+
+		// Stopped on timeout.
+		_, err := client.Create("/node")
+		assert.NoError(t, err)
+		select {
+		case e := <-events:
+			t.Fatalf("Unexpected event: %v", e)
+		case <-time.After(time.Second):
+		}
+	})
+}
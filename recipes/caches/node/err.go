@@ -0,0 +1,10 @@
+package node
+
+import "errors"
+
+var (
+	// ErrAlreadyStarted indicates Start is called more than once.
+	ErrAlreadyStarted = errors.New("already started")
+	// ErrWaitInitTimeout indicates the cache is not initialized in time.
+	ErrWaitInitTimeout = errors.New("waiting for initialization timed out")
+)
@@ -0,0 +1,53 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/tevino/zoo/recipes/caches/tree"
+)
+
+// EventType represents the type of change to the node.
+type EventType int
+
+const (
+	// EventNodeCreated indicates the node was created.
+	EventNodeCreated EventType = iota
+	// EventNodeUpdated indicates the data of the node was changed.
+	EventNodeUpdated
+	// EventNodeDeleted indicates the node was deleted.
+	EventNodeDeleted
+)
+
+// String returns the string representation of EventType.
+// "Unknown" is returned when event type is unknown
+func (et EventType) String() string {
+	switch et {
+	case EventNodeCreated:
+		return "NodeCreated"
+	case EventNodeUpdated:
+		return "NodeUpdated"
+	case EventNodeDeleted:
+		return "NodeDeleted"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event represents a change to the node.
+type Event struct {
+	Type EventType
+	// Data is the data of the node after the change, it's the data before
+	// deletion for EventNodeDeleted.
+	Data *tree.ChildData
+}
+
+// String returns the string representation of Event
+func (e Event) String() string {
+	var path string
+	var data []byte
+	if e.Data != nil {
+		path = e.Data.Path()
+		data = e.Data.Data()
+	}
+	return fmt.Sprintf("Event{%s %s '%s'}", e.Type, path, data)
+}
@@ -0,0 +1,16 @@
+package node
+
+// EventListener is a handler of cache events.
+type EventListener struct {
+	fn func(Event)
+}
+
+// Handle calls the function with e.
+func (l *EventListener) Handle(e Event) {
+	l.fn(e)
+}
+
+// NewEventListener creates EventListener with fn.
+func NewEventListener(fn func(Event)) *EventListener {
+	return &EventListener{fn}
+}
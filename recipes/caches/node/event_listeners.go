@@ -0,0 +1,30 @@
+package node
+
+import "github.com/tevino/zoo/enhanced"
+
+// EventListeners is a container of EventListeners.
+type EventListeners struct {
+	*enhanced.ListenerContainer
+}
+
+// NewEventListeners creates empty EventListeners.
+func NewEventListeners() *EventListeners {
+	return &EventListeners{enhanced.NewListenerContainer()}
+}
+
+// Add adds a Listener.
+func (l *EventListeners) Add(listener *EventListener) {
+	l.ListenerContainer.Add(listener)
+}
+
+// Del deletes a Listener.
+func (l *EventListeners) Del(listener *EventListener) {
+	l.ListenerContainer.Del(listener)
+}
+
+// Broadcast calls every listener with given Event.
+func (l *EventListeners) Broadcast(e Event) {
+	l.ListenerContainer.Foreach(func(listener interface{}) {
+		listener.(*EventListener).Handle(e)
+	})
+}
@@ -1,11 +1,14 @@
 package tree
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 
@@ -24,16 +27,23 @@ type Cache struct {
 	// Tracks the number of outstanding background requests in flight. The first time this count reaches 0, we publish the initialized event.
 	outstandingOps uint64
 	isInitialized  *abool.AtomicBool
+	initLock       sync.Mutex
+	// initialized is closed once the cache is initialized.
+	initialized    chan struct{}
 	root           *Node
 	client         *enhanced.Client
 	cacheData      bool
 	maxDepth       int
 	selector       Selector
 	eventListeners *CacheEventListeners
+	eventChans     *eventChans
 	errorListeners *ErrorListeners
 	state          CacheState
-	// connectionStateListener curator.ConnectionStateListener
-	// logger                  Logger
+	stateListener  *enhanced.StateChangeListener
+	// sessionLost is set once the session expires, the tree is watched again
+	// after the next reconnection since all watches are gone.
+	sessionLost  *abool.AtomicBool
+	logger       enhanced.Logger
 	createParent bool
 }
 
@@ -49,20 +59,23 @@ func NewCache(client *enhanced.Client, root string, selector Selector) *Cache {
 	}
 	var cache = &Cache{
 		isInitialized:  abool.New(),
+		initialized:    make(chan struct{}),
 		client:         client,
 		maxDepth:       math.MaxInt32,
 		cacheData:      true,
 		selector:       selector,
 		state:          CacheStateLatent,
 		eventListeners: NewCacheEventListeners(),
+		eventChans:     newEventChans(),
 		errorListeners: NewErrorListeners(),
-		// logger: &DummyLogger{},
+		sessionLost:    abool.New(),
+		logger:         enhanced.NopLogger,
+	}
+	if client != nil {
+		cache.logger = client.Logger()
 	}
 	cache.root = NewNode(cache, root, nil)
-	// cache.connectionStateListener = curator.NewConnectionStateListener(
-	// 	func(client curator.CuratorFramework, newState curator.ConnectionState) {
-	// 		c.handleStateChange(newState)
-	// 	})
+	cache.stateListener = enhanced.NewStateChangeListener(cache.handleStateChange)
 	return cache
 }
 
@@ -81,12 +94,55 @@ func (c *Cache) Start() error {
 		// TODO: allow disconnected?
 		return errors.New("client not connected")
 	}
+	c.client.AddStateChangeListener(c.stateListener)
 	c.root.wasCreated()
 	return nil
 }
 
+// StartAndWait starts the cache then waits until it's initialized.
+// ErrWaitInitTimeout is returned if it's not initialized within timeout, the
+// cache is stopped in that case.
+func (c *Cache) StartAndWait(timeout time.Duration) error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := c.WaitInitialized(ctx); err != nil {
+		c.Stop()
+		return ErrWaitInitTimeout
+	}
+	return nil
+}
+
+// WaitInitialized blocks until the cache is initialized or ctx is done.
+// The error of ctx is returned if ctx is done first.
+// NOTE: The cache is no longer initialized once the session is lost, until
+// it gets fully populated again.
+func (c *Cache) WaitInitialized(ctx context.Context) error {
+	c.initLock.Lock()
+	var initialized = c.initialized
+	c.initLock.Unlock()
+	select {
+	case <-initialized:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsInitialized returns true if the initial cache has been fully populated.
+func (c *Cache) IsInitialized() bool {
+	return c.isInitialized.IsSet()
+}
+
+// OutstandingOps returns the number of outstanding background requests in flight.
+func (c *Cache) OutstandingOps() uint64 {
+	return atomic.LoadUint64(&c.outstandingOps)
+}
+
 func (c *Cache) createParentNodes() error {
-	var err = c.client.CreateWithParents(c.root.path)
+	var _, err = c.client.CreateWithParents(c.root.path)
 	if err == zk.ErrNodeExists {
 		err = nil
 	} else if err != nil {
@@ -120,18 +176,20 @@ func (c *Cache) SetCreateParentNodes(yes bool) *Cache {
 	return c
 }
 
-// // SetLogger sets the inner Logger of TreeCache.
-// func (c *Cache) SetLogger(l Logger) *Cache {
-// 	c.logger = l
-// 	return c
-// }
+// SetLogger sets the inner Logger of TreeCache.
+// Default to the Logger of the client. It must be called before Start.
+func (c *Cache) SetLogger(l enhanced.Logger) *Cache {
+	c.logger = l
+	return c
+}
 
 // Stop stops the cache.
 func (c *Cache) Stop() {
 	if c.state.SetValueIf(CacheStateStarted, CacheStateStopped) {
-		// c.client.ConnectionStateListenable().RemoveListener(c.connectionStateListener)
+		c.client.DelStateChangeListener(c.stateListener)
 		// c.listeners.Clear()
 		c.root.wasDeleted()
+		c.eventChans.Close()
 	}
 }
 
@@ -145,6 +203,20 @@ func (c *Cache) DelEventListener(l *CacheEventListener) {
 	c.eventListeners.Del(l)
 }
 
+// Events returns a channel receiving all events of the cache in the order they
+// are published, further events are queued until the events are received.
+// The channel is closed once the cache is stopped.
+func (c *Cache) Events(bufferSize int) <-chan CacheEvent {
+	return c.EventsWithPolicy(bufferSize, OverflowBlock)
+}
+
+// EventsWithPolicy is Events with the OverflowPolicy applied when the channel
+// is full.
+// NOTE: bufferSize is at least 1 for OverflowDropOldest.
+func (c *Cache) EventsWithPolicy(bufferSize int, policy OverflowPolicy) <-chan CacheEvent {
+	return c.eventChans.Add(bufferSize, policy)
+}
+
 // AddErrorListener adds an error listener.
 func (c *Cache) AddErrorListener(l *ErrorListener) {
 	c.errorListeners.Add(l)
@@ -237,33 +309,43 @@ func (c *Cache) CurrentData(fullPath string) (*ChildData, error) {
 // handleException sends an exception to all listeners, or else log the error if there are none.
 func (c *Cache) handleException(e error) {
 	if c.errorListeners.Count() == 0 {
-		// c.logger.Printf("%s", e)
+		c.logger.Error("unhandled error", "root", c.root.path, "err", e)
 		return
 	}
 	c.errorListeners.Broadcast(e)
 }
 
-// func (c *Cache) handleStateChange(newState curator.ConnectionState) {
-// 	switch newState {
-// 	case curator.SUSPENDED:
-// 		c.publishEvent(CacheEventConnSuspended, nil)
-// 	case curator.LOST:
-// 		c.isInitialized.UnSet()
-// 		c.publishEvent(CacheEventConnLost, nil)
-// 	case curator.CONNECTED:
-// 		c.root.wasCreated()
-// 	case curator.RECONNECTED:
-// 		if err := c.root.wasReconnected(); err == nil {
-// 			c.publishEvent(CacheEventConnReconnected, nil)
-// 		}
-// 	}
-// }
+// handleStateChange reacts to connection state transitions of the client.
+func (c *Cache) handleStateChange(newState enhanced.ConnState) {
+	if !c.state.EqualTo(CacheStateStarted) {
+		return
+	}
+	c.logger.Debug("handleStateChange", "root", c.root.path, "state", newState)
+	switch newState {
+	case enhanced.ConnStateSuspended:
+		c.publishEvent(CacheEventConnSuspended, nil)
+	case enhanced.ConnStateLost:
+		c.sessionLost.Set()
+		c.resetInitialized()
+		c.publishEvent(CacheEventConnLost, nil)
+	case enhanced.ConnStateConnected:
+		c.root.wasCreated()
+	case enhanced.ConnStateReconnected:
+		// Watches survive reconnections within the same session, watching
+		// again would add duplicate watchers.
+		if c.sessionLost.SetToIf(true, false) {
+			c.root.wasReconnected()
+		}
+		c.publishEvent(CacheEventConnReconnected, nil)
+	}
+}
 
 // publishEvent publish an event with given type and data to all listeners.
 func (c *Cache) publishEvent(tp CacheEventType, data *ChildData) {
 	if !c.state.EqualTo(CacheStateStopped) {
 		var evt = CacheEvent{Type: tp, Data: data}
-		// c.logger.Debugf("publishEvent: %v", evt)
+		c.logger.Debug("publishEvent", "event", evt)
+		c.eventChans.Broadcast(evt)
 		go c.eventListeners.Broadcast(evt)
 	}
 }
@@ -286,9 +368,29 @@ func (c *Cache) incOutstandingOpsBy(n int) {
 func (c *Cache) completeOutstandingOps() {
 	// Decrease by 1
 	if atomic.AddUint64(&c.outstandingOps, ^uint64(0)) == 0 {
-		if !c.isInitialized.IsSet() {
-			c.isInitialized.Set()
+		if c.setInitialized() {
 			c.publishEvent(CacheEventInitialized, nil)
 		}
 	}
 }
+
+// setInitialized marks the cache initialized.
+// The returning value indicates whether it was not initialized.
+func (c *Cache) setInitialized() bool {
+	c.initLock.Lock()
+	defer c.initLock.Unlock()
+	if !c.isInitialized.SetToIf(false, true) {
+		return false
+	}
+	close(c.initialized)
+	return true
+}
+
+// resetInitialized marks the cache not initialized.
+func (c *Cache) resetInitialized() {
+	c.initLock.Lock()
+	defer c.initLock.Unlock()
+	if c.isInitialized.SetToIf(true, false) {
+		c.initialized = make(chan struct{})
+	}
+}
@@ -1,10 +1,12 @@
 package tree
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
 	"github.com/tevino/zoo/test"
 )
 
@@ -40,3 +42,112 @@ func TestInitEvent(t *testing.T) {
 		}
 	})
 }
+
+func TestConnStateEvents(t *testing.T) {
+	var cache = NewCache(nil, "/", nil)
+	cache.state = CacheStateStarted
+	cache.isInitialized.Set()
+
+	var events = make(chan CacheEvent, 1)
+	cache.AddEventListener(NewCacheEventListener(func(e CacheEvent) {
+		events <- e
+	}))
+
+	for _, c := range []struct {
+		state enhanced.ConnState
+		event CacheEventType
+	}{
+		{enhanced.ConnStateSuspended, CacheEventConnSuspended},
+		{enhanced.ConnStateLost, CacheEventConnLost},
+	} {
+		cache.handleStateChange(c.state)
+		select {
+		case <-time.After(time.Second):
+			t.Fatalf("Waiting for %s timed out", c.event)
+		case e := <-events:
+			assert.Equal(t, c.event, e.Type)
+		}
+	}
+	assert.False(t, cache.isInitialized.IsSet())
+}
+
+func TestWaitInitialized(t *testing.T) {
+	var cache = NewCache(nil, "/", nil)
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, cache.WaitInitialized(ctx))
+	assert.False(t, cache.IsInitialized())
+
+	cache.incOutstandingOpsBy(2)
+	assert.Equal(t, uint64(2), cache.OutstandingOps())
+	cache.completeOutstandingOps()
+	assert.False(t, cache.IsInitialized())
+	cache.completeOutstandingOps()
+	assert.Equal(t, uint64(0), cache.OutstandingOps())
+	assert.True(t, cache.IsInitialized())
+	assert.NoError(t, cache.WaitInitialized(context.Background()))
+
+	cache.resetInitialized()
+	assert.False(t, cache.IsInitialized())
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, cache.WaitInitialized(ctx))
+}
+
+func nextEvent(t *testing.T, events <-chan CacheEvent) CacheEvent {
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second * 5):
+		t.Fatal("Waiting for event timed out")
+	}
+	return CacheEvent{}
+}
+
+func TestReconnectWithoutDuplicateEvents(t *testing.T) {
+	test.NewZkEnv(t).With(func(zkEnv *test.ZkEnv) {
+		var client, proxy = zkEnv.NewProxiedClientTimeout(time.Second * 2)
+		var other = zkEnv.NewClientTimeout(time.Second * 2)
+		_, err := other.CreateValueWithParents("/root/child", []byte("a"))
+		assert.NoError(t, err)
+
+		var cache = NewCache(client, "/root", nil)
+		var events = cache.Events(100)
+		assert.NoError(t, cache.StartAndWait(time.Second*5))
+		defer cache.Stop()
+		for e := nextEvent(t, events); e.Type != CacheEventInitialized; e = nextEvent(t, events) {
+			assert.Equal(t, CacheEventNodeAdded, e.Type)
+		}
+
+		for i := 0; i < 2; i++ {
+			proxy.Interrupt(time.Millisecond * 500)
+			assert.Equal(t, CacheEventConnSuspended, nextEvent(t, events).Type)
+			assert.Equal(t, CacheEventConnReconnected, nextEvent(t, events).Type)
+		}
+
+		_, err = other.Set("/root/child", []byte("b"), -1)
+		assert.NoError(t, err)
+		var e = nextEvent(t, events)
+		assert.Equal(t, CacheEventNodeUpdated, e.Type)
+		assert.Equal(t, "b", string(e.Data.Data()))
+		select {
+		case e := <-events:
+			t.Fatalf("Unexpected event: %v", e)
+		case <-time.After(time.Second):
+		}
+	})
+}
+
+func TestStartAndWaitTimeout(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.Client()
+		var cache = NewCache(client, "/", nil)
+		var events = cache.Events(10)
+		assert.Equal(t, ErrWaitInitTimeout, cache.StartAndWait(0))
+
+		// Stopped on timeout.
+		for range events {
+		}
+		assert.Error(t, cache.Start())
+	})
+}
@@ -9,4 +9,6 @@ var (
 	ErrRootNotMatch = errors.New("root path not match")
 	// ErrNodeNotLive indicates the state of node is not LIVE.
 	ErrNodeNotLive = errors.New("node state is not LIVE")
+	// ErrWaitInitTimeout indicates the cache is not initialized in time.
+	ErrWaitInitTimeout = errors.New("waiting for initialization timed out")
 )
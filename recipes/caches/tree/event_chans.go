@@ -0,0 +1,154 @@
+package tree
+
+import "sync"
+
+// eventChan is a channel of CacheEvent with an OverflowPolicy.
+type eventChan struct {
+	ch     chan CacheEvent
+	policy OverflowPolicy
+}
+
+// send sends e to the channel according to the OverflowPolicy.
+// Blocking sending is canceled once stopped is closed.
+func (c *eventChan) send(e CacheEvent, stopped <-chan struct{}) {
+	if c.policy == OverflowDropOldest {
+		for {
+			select {
+			case c.ch <- e:
+				return
+			default:
+			}
+			select {
+			case <-c.ch:
+			default:
+			}
+		}
+	}
+	select {
+	case c.ch <- e:
+	case <-stopped:
+	}
+}
+
+// eventChans is a set of channels receiving CacheEvents in order.
+// Events are sent from a dispatching goroutine, so Broadcast never blocks the
+// caller even if a channel with OverflowBlock is full.
+type eventChans struct {
+	sync.Mutex
+	chans       []*eventChan
+	queue       []CacheEvent
+	queued      chan struct{}
+	dispatching bool
+	done        chan struct{}
+	stopped     chan struct{}
+	closed      bool
+	closeOnce   sync.Once
+}
+
+// newEventChans creates empty eventChans.
+func newEventChans() *eventChans {
+	return &eventChans{
+		queued:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Add creates a channel with given buffer size and OverflowPolicy.
+// A closed channel is returned if eventChans is closed.
+func (l *eventChans) Add(bufferSize int, policy OverflowPolicy) <-chan CacheEvent {
+	if policy == OverflowDropOldest && bufferSize < 1 {
+		bufferSize = 1
+	}
+	var c = &eventChan{ch: make(chan CacheEvent, bufferSize), policy: policy}
+	l.Lock()
+	defer l.Unlock()
+	if l.closed {
+		close(c.ch)
+	} else {
+		l.chans = append(l.chans, c)
+	}
+	return c.ch
+}
+
+// Broadcast queues e to be sent to every channel in order.
+func (l *eventChans) Broadcast(e CacheEvent) {
+	l.Lock()
+	if l.closed {
+		l.Unlock()
+		return
+	}
+	l.queue = append(l.queue, e)
+	if !l.dispatching {
+		l.dispatching = true
+		go l.dispatch()
+	}
+	l.Unlock()
+
+	select {
+	case l.queued <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch sends queued events to every channel until closed.
+func (l *eventChans) dispatch() {
+	defer close(l.done)
+	for {
+		select {
+		case <-l.queued:
+		case <-l.stopped:
+			return
+		}
+		for {
+			var e, chans, ok = l.dequeue()
+			if !ok {
+				break
+			}
+			for _, c := range chans {
+				c.send(e, l.stopped)
+			}
+			select {
+			case <-l.stopped:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// dequeue pops the first queued event with the channels to receive it.
+func (l *eventChans) dequeue() (CacheEvent, []*eventChan, bool) {
+	l.Lock()
+	defer l.Unlock()
+	if len(l.queue) == 0 {
+		return CacheEvent{}, nil, false
+	}
+	var e = l.queue[0]
+	l.queue[0] = CacheEvent{}
+	l.queue = l.queue[1:]
+	return e, append([]*eventChan(nil), l.chans...), true
+}
+
+// Close closes all channels, events are no longer sent after that.
+func (l *eventChans) Close() {
+	l.closeOnce.Do(func() {
+		// Cancel blocking sending of the dispatching goroutine.
+		close(l.stopped)
+		l.Lock()
+		l.closed = true
+		var dispatching = l.dispatching
+		l.queue = nil
+		l.Unlock()
+		if dispatching {
+			<-l.done
+		}
+
+		l.Lock()
+		defer l.Unlock()
+		for _, c := range l.chans {
+			close(c.ch)
+		}
+		l.chans = nil
+	})
+}
@@ -0,0 +1,63 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventChansOrder(t *testing.T) {
+	var cache = NewCache(nil, "/", nil)
+	var events = cache.Events(10)
+	var types = []CacheEventType{CacheEventNodeAdded, CacheEventNodeUpdated, CacheEventNodeRemoved, CacheEventInitialized}
+	for _, tp := range types {
+		cache.publishEvent(tp, nil)
+	}
+	for _, tp := range types {
+		assert.Equal(t, tp, (<-events).Type)
+	}
+}
+
+func TestEventChansDropOldest(t *testing.T) {
+	var ls = newEventChans()
+	var events = ls.Add(2, OverflowDropOldest)
+	// Events are sent to channels in the order they are added.
+	var sentinel = ls.Add(3, OverflowBlock)
+	ls.Broadcast(CacheEvent{Type: CacheEventNodeAdded})
+	ls.Broadcast(CacheEvent{Type: CacheEventNodeUpdated})
+	ls.Broadcast(CacheEvent{Type: CacheEventNodeRemoved})
+	for i := 0; i < 3; i++ {
+		<-sentinel
+	}
+	assert.Equal(t, CacheEventNodeUpdated, (<-events).Type)
+	assert.Equal(t, CacheEventNodeRemoved, (<-events).Type)
+}
+
+func TestEventChansNotBlocking(t *testing.T) {
+	var ls = newEventChans()
+	var events = ls.Add(0, OverflowBlock)
+	// Nobody is receiving.
+	for i := 0; i < 10; i++ {
+		ls.Broadcast(CacheEvent{Type: CacheEventNodeAdded})
+	}
+	ls.Broadcast(CacheEvent{Type: CacheEventNodeRemoved})
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, CacheEventNodeAdded, (<-events).Type)
+	}
+	assert.Equal(t, CacheEventNodeRemoved, (<-events).Type)
+	ls.Close()
+}
+
+func TestEventChansClose(t *testing.T) {
+	var ls = newEventChans()
+	var events = ls.Add(0, OverflowBlock)
+	ls.Broadcast(CacheEvent{})
+	ls.Broadcast(CacheEvent{})
+	// Sending blocked by the receiver is canceled.
+	ls.Close()
+	for range events {
+	}
+	ls.Broadcast(CacheEvent{})
+	_, ok := <-ls.Add(1, OverflowBlock)
+	assert.False(t, ok)
+}
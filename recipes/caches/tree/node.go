@@ -118,14 +118,13 @@ func (n *Node) doRefreshData() {
 	// ).InBackgroundWithCallback(n.processResult).ForPath(n.path)
 }
 
-func (n *Node) wasReconnected() error {
+// wasReconnected watches the node and its descendants again once the session
+// expired.
+func (n *Node) wasReconnected() {
 	n.refresh()
 	for _, child := range n.Children() {
-		if err := child.wasReconnected(); err != nil {
-			return err
-		}
+		child.wasReconnected()
 	}
-	return nil
 }
 
 func (n *Node) wasCreated() {
@@ -161,7 +160,7 @@ func (n *Node) wasDeleted() {
 
 // processWatchEvent processes watch events.
 func (n *Node) processWatchEvent(evt zk.Event) {
-	// n.cache.logger.Debugf("ProcessWatchEvent: %v", evt)
+	n.cache.logger.Debug("processWatchEvent", "path", n.path, "event", evt)
 	switch evt.Type {
 	case zk.EventNodeCreated:
 		if n.parent != nil {
@@ -177,7 +176,7 @@ func (n *Node) processWatchEvent(evt zk.Event) {
 		n.wasDeleted()
 	default:
 		// Leave other type of events unhandled
-		// n.cache.logger.Printf("Event received: %v", evt)
+		n.cache.logger.Debug("unhandled watch event", "path", n.path, "event", evt)
 	}
 }
 
@@ -214,6 +213,8 @@ func (n *Node) processChildrenResult(result enhanced.ChildrenResult) {
 			}
 			n.Unlock()
 		}
+	default:
+		n.cache.logger.Warn("unknown GET_CHILDREN error", "path", result.Path, "err", result.Err)
 	}
 
 	n.cache.completeOutstandingOps()
@@ -226,13 +227,15 @@ func (n *Node) processExistResult(result enhanced.ExistResult) {
 	if result.Err == nil {
 		n.state.SetValueIf(NodeStateDead, NodeStatePending)
 		n.wasCreated()
+	} else {
+		n.cache.logger.Warn("unknown EXISTS error", "path", result.Path, "err", result.Err)
 	}
 
 	n.cache.completeOutstandingOps()
 }
 
 func (n *Node) processDataResult(result enhanced.DataResult) {
-	// n.cache.logger.Debugf("ProcessResult: %v", evt)
+	n.cache.logger.Debug("processDataResult", "path", n.path, "err", result.Err)
 	var newStat = result.Stat
 
 	// case curator.CHILDREN:
@@ -271,7 +274,7 @@ func (n *Node) processDataResult(result enhanced.DataResult) {
 			}
 		}
 	default:
-		// n.cache.logger.Printf("Unknown GET_DATA event[%v]: %s", evt.Path(), evt.Err())
+		n.cache.logger.Warn("unknown GET_DATA error", "path", result.Path, "err", result.Err)
 	}
 
 	n.cache.completeOutstandingOps()
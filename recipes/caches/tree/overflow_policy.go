@@ -0,0 +1,12 @@
+package tree
+
+// OverflowPolicy decides what to do when the channel of events is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock holds further events until the event is received.
+	// Events are queued in the meantime, the cache itself is not blocked.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest event in the channel.
+	OverflowDropOldest
+)
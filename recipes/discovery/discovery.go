@@ -0,0 +1,235 @@
+// Package discovery contains a recipe of service discovery.
+package discovery
+
+import (
+	"path"
+	"sync"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/abool"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// DefaultBasePath is the default path under which services are registered.
+const DefaultBasePath = "/services"
+
+// Discovery registers and queries instances of services.
+//
+// Instances of a service are registered under <base path>/<service name>.
+// Since they are ephemeral, a started Discovery registers its instances
+// again once the connection is RECONNECTED, in case the session expired.
+type Discovery struct {
+	client        *enhanced.Client
+	basePath      string
+	logger        enhanced.Logger
+	stateListener *enhanced.StateChangeListener
+	started       *abool.AtomicBool
+
+	lock       sync.Mutex
+	registered map[string]*Instance
+}
+
+// NewDiscovery creates a Discovery of services under basePath.
+func NewDiscovery(client *enhanced.Client, basePath string) *Discovery {
+	var d = &Discovery{
+		client:     client,
+		basePath:   path.Join("/", basePath),
+		logger:     enhanced.NopLogger,
+		started:    abool.New(),
+		registered: make(map[string]*Instance),
+	}
+	if client != nil {
+		d.logger = client.Logger()
+	}
+	d.stateListener = enhanced.NewStateChangeListener(d.handleStateChange)
+	return d
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (d *Discovery) SetLogger(logger enhanced.Logger) *Discovery {
+	d.logger = logger
+	return d
+}
+
+// Start starts keeping registered instances registered.
+func (d *Discovery) Start() error {
+	if !d.started.SetToIf(false, true) {
+		return ErrAlreadyStarted
+	}
+	d.client.AddStateChangeListener(d.stateListener)
+	return nil
+}
+
+// Close unregisters all instances registered by the Discovery.
+func (d *Discovery) Close() error {
+	if !d.started.SetToIf(true, false) {
+		return ErrNotStarted
+	}
+	d.client.DelStateChangeListener(d.stateListener)
+	d.lock.Lock()
+	var instances = make([]*Instance, 0, len(d.registered))
+	for _, i := range d.registered {
+		instances = append(instances, i)
+	}
+	d.lock.Unlock()
+
+	var lastErr error
+	for _, i := range instances {
+		if err := d.UnregisterInstance(i); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// RegisterInstance registers i.
+func (d *Discovery) RegisterInstance(i *Instance) error {
+	if err := d.register(i); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	d.registered[i.ID] = i
+	d.lock.Unlock()
+	return nil
+}
+
+// UpdateInstance updates the registered data of i.
+func (d *Discovery) UpdateInstance(i *Instance) error {
+	var data, err = encodeInstance(i)
+	if err != nil {
+		return err
+	}
+	if _, err = d.client.Set(d.instancePath(i.Name, i.ID), data, -1); err != nil {
+		return err
+	}
+	d.lock.Lock()
+	if _, ok := d.registered[i.ID]; ok {
+		d.registered[i.ID] = i
+	}
+	d.lock.Unlock()
+	return nil
+}
+
+// UnregisterInstance unregisters i, it's not an error if i is not registered.
+func (d *Discovery) UnregisterInstance(i *Instance) error {
+	d.lock.Lock()
+	delete(d.registered, i.ID)
+	d.lock.Unlock()
+	var err = d.client.Delete(d.instancePath(i.Name, i.ID), -1)
+	if err == zk.ErrNoNode {
+		err = nil
+	}
+	return err
+}
+
+// QueryNames returns names of all services.
+func (d *Discovery) QueryNames() ([]string, error) {
+	var names, _, err = d.client.GetChildren(d.basePath)
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	return names, err
+}
+
+// QueryInstances returns all instances of the service named name.
+func (d *Discovery) QueryInstances(name string) ([]*Instance, error) {
+	var ids, _, err = d.client.GetChildren(d.servicePath(name))
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var instances = make([]*Instance, 0, len(ids))
+	for _, id := range ids {
+		var i, err = d.QueryInstance(name, id)
+		if err == ErrNoInstance {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, i)
+	}
+	return instances, nil
+}
+
+// QueryInstance returns the instance of the service named name with given id.
+// ErrNoInstance is returned if there is no such instance.
+func (d *Discovery) QueryInstance(name string, id string) (*Instance, error) {
+	var data, _, err = d.client.Get(d.instancePath(name, id))
+	if err == zk.ErrNoNode {
+		return nil, ErrNoInstance
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeInstance(data)
+}
+
+// NewServiceProvider creates a ServiceProvider of the service named name.
+func (d *Discovery) NewServiceProvider(name string) *ServiceProvider {
+	return newServiceProvider(d.client, d.servicePath(name)).SetLogger(d.logger)
+}
+
+func (d *Discovery) servicePath(name string) string {
+	return path.Join(d.basePath, name)
+}
+
+func (d *Discovery) instancePath(name string, id string) string {
+	return path.Join(d.basePath, name, id)
+}
+
+// register creates the node of i, a node left by an expired session is
+// replaced.
+func (d *Discovery) register(i *Instance) error {
+	var data, err = encodeInstance(i)
+	if err != nil {
+		return err
+	}
+	var p = d.instancePath(i.Name, i.ID)
+	if err = znodes.EnsurePath(d.client, d.servicePath(i.Name)); err != nil {
+		return err
+	}
+	_, err = d.client.CreateValue(p, data, enhanced.WithMode(enhanced.ModeEphemeral))
+	if err != zk.ErrNodeExists {
+		return err
+	}
+	var _, stat, getErr = d.client.Get(p)
+	if getErr == zk.ErrNoNode {
+		return d.register(i)
+	}
+	if getErr != nil {
+		return getErr
+	}
+	if stat.EphemeralOwner == d.client.Conn().SessionID() {
+		_, err = d.client.Set(p, data, -1)
+		return err
+	}
+	if err = d.client.Delete(p, stat.Version); err != nil && err != zk.ErrNoNode {
+		return err
+	}
+	return d.register(i)
+}
+
+func (d *Discovery) handleStateChange(s enhanced.ConnState) {
+	if s == enhanced.ConnStateReconnected {
+		go d.reregisterAll()
+	}
+}
+
+func (d *Discovery) reregisterAll() {
+	d.lock.Lock()
+	var instances = make([]*Instance, 0, len(d.registered))
+	for _, i := range d.registered {
+		instances = append(instances, i)
+	}
+	d.lock.Unlock()
+	for _, i := range instances {
+		if err := d.register(i); err != nil {
+			d.logger.Error("registering service instance", "name", i.Name, "id", i.ID, "err", err)
+		}
+	}
+}
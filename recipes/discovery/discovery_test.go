@@ -0,0 +1,56 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestDiscovery(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		var d = NewDiscovery(client, DefaultBasePath)
+		assert.NoError(t, d.Start())
+		defer d.Close()
+
+		var a = NewInstance("api", "10.0.0.1", 8080)
+		assert.NoError(t, a.SetPayload(map[string]string{"zone": "a"}))
+		var b = NewInstance("api", "10.0.0.2", 8080)
+		assert.NoError(t, d.RegisterInstance(a))
+		assert.NoError(t, d.RegisterInstance(b))
+
+		var names, err = d.QueryNames()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"api"}, names)
+		instances, err := d.QueryInstances("api")
+		assert.NoError(t, err)
+		assert.Len(t, instances, 2)
+		got, err := d.QueryInstance("api", a.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, a.Endpoint(), got.Endpoint())
+		var payload map[string]string
+		assert.NoError(t, got.DecodePayload(&payload))
+		assert.Equal(t, "a", payload["zone"])
+
+		var provider = d.NewServiceProvider("api")
+		assert.NoError(t, provider.Start())
+		defer provider.Close()
+		assert.Len(t, provider.Instances(), 2)
+
+		provider.NoteError(a)
+		for i := 0; i < 4; i++ {
+			picked, err := provider.Instance()
+			assert.NoError(t, err)
+			assert.Equal(t, b.ID, picked.ID)
+		}
+
+		assert.NoError(t, d.UnregisterInstance(b))
+		time.Sleep(time.Millisecond * 500)
+		_, err = provider.Instance()
+		assert.Equal(t, ErrNoInstance, err)
+		_, err = d.QueryInstance("api", b.ID)
+		assert.Equal(t, ErrNoInstance, err)
+	})
+}
@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"sync"
+	"time"
+)
+
+// downInstances tracks errors of instances, an instance is down once errors
+// reach the threshold, until timeout since the first error.
+type downInstances struct {
+	timeout   time.Duration
+	threshold int
+
+	lock    sync.Mutex
+	entries map[string]*downEntry
+}
+
+type downEntry struct {
+	errors int
+	since  time.Time
+}
+
+func newDownInstances(timeout time.Duration, threshold int) *downInstances {
+	return &downInstances{
+		timeout:   timeout,
+		threshold: threshold,
+		entries:   make(map[string]*downEntry),
+	}
+}
+
+// add records an error of the instance.
+func (d *downInstances) add(id string, now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.purge(now)
+	var e, ok = d.entries[id]
+	if !ok {
+		e = &downEntry{since: now}
+		d.entries[id] = e
+	}
+	e.errors++
+}
+
+// filter returns instances which are not down.
+func (d *downInstances) filter(instances []*Instance, now time.Time) []*Instance {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.purge(now)
+	var result = make([]*Instance, 0, len(instances))
+	for _, i := range instances {
+		if e, ok := d.entries[i.ID]; !ok || e.errors < d.threshold {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func (d *downInstances) purge(now time.Time) {
+	for id, e := range d.entries {
+		if now.Sub(e.since) >= d.timeout {
+			delete(d.entries, id)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package discovery
+
+import "errors"
+
+var (
+	// ErrAlreadyStarted indicates Start is called more than once.
+	ErrAlreadyStarted = errors.New("already started")
+	// ErrNotStarted indicates Close is called before Start.
+	ErrNotStarted = errors.New("not started")
+	// ErrNoInstance indicates there is no instance available.
+	ErrNoInstance = errors.New("no instance available")
+)
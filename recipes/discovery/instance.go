@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// Instance is an instance of a service.
+//
+// It's registered as an ephemeral znode named by its ID under the path of
+// the service, the data of which is the Instance serialized as JSON.
+type Instance struct {
+	Name             string          `json:"name"`
+	ID               string          `json:"id"`
+	Address          string          `json:"address"`
+	Port             int             `json:"port"`
+	Payload          json.RawMessage `json:"payload,omitempty"`
+	RegistrationTime time.Time       `json:"registrationTime"`
+}
+
+// NewInstance creates an Instance of the service named name with a random ID.
+func NewInstance(name string, address string, port int) *Instance {
+	return &Instance{
+		Name:             name,
+		ID:               znodes.NewToken(),
+		Address:          address,
+		Port:             port,
+		RegistrationTime: time.Now(),
+	}
+}
+
+// SetPayload sets the payload to v serialized as JSON.
+func (i *Instance) SetPayload(v interface{}) error {
+	var data, err = json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	i.Payload = data
+	return nil
+}
+
+// DecodePayload deserializes the payload into v.
+func (i *Instance) DecodePayload(v interface{}) error {
+	return json.Unmarshal(i.Payload, v)
+}
+
+// Endpoint returns the address and port joined as "host:port".
+func (i *Instance) Endpoint() string {
+	return net.JoinHostPort(i.Address, strconv.Itoa(i.Port))
+}
+
+func encodeInstance(i *Instance) ([]byte, error) {
+	return json.Marshal(i)
+}
+
+func decodeInstance(data []byte) (*Instance, error) {
+	var i = new(Instance)
+	if err := json.Unmarshal(data, i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
@@ -0,0 +1,16 @@
+package discovery
+
+import "math/rand"
+
+// RandomStrategy picks an instance randomly.
+type RandomStrategy struct{}
+
+// NewRandomStrategy creates a RandomStrategy.
+func NewRandomStrategy() *RandomStrategy {
+	return &RandomStrategy{}
+}
+
+// Pick implements Strategy.
+func (s *RandomStrategy) Pick(instances []*Instance) *Instance {
+	return instances[rand.Intn(len(instances))]
+}
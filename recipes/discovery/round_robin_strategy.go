@@ -0,0 +1,19 @@
+package discovery
+
+import "sync/atomic"
+
+// RoundRobinStrategy picks instances in turn.
+type RoundRobinStrategy struct {
+	index uint64
+}
+
+// NewRoundRobinStrategy creates a RoundRobinStrategy.
+func NewRoundRobinStrategy() *RoundRobinStrategy {
+	return &RoundRobinStrategy{}
+}
+
+// Pick implements Strategy.
+func (s *RoundRobinStrategy) Pick(instances []*Instance) *Instance {
+	var i = atomic.AddUint64(&s.index, 1) - 1
+	return instances[i%uint64(len(instances))]
+}
@@ -0,0 +1,115 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/caches/children"
+)
+
+const (
+	// DefaultDownTimeout is the default time an instance is down for.
+	DefaultDownTimeout = 30 * time.Second
+	// DefaultDownThreshold is the default number of errors to down an
+	// instance.
+	DefaultDownThreshold = 1
+)
+
+// ServiceProvider provides instances of a service picked by a Strategy.
+//
+// Instances are watched by a children cache. Callers report instances
+// failing by NoteError, which are not provided for a while after enough
+// errors.
+type ServiceProvider struct {
+	path     string
+	cache    *children.Cache
+	strategy Strategy
+	downs    *downInstances
+	logger   enhanced.Logger
+}
+
+func newServiceProvider(client *enhanced.Client, p string) *ServiceProvider {
+	return &ServiceProvider{
+		path:     p,
+		cache:    children.NewCache(client, p),
+		strategy: NewRoundRobinStrategy(),
+		downs:    newDownInstances(DefaultDownTimeout, DefaultDownThreshold),
+		logger:   enhanced.NopLogger,
+	}
+}
+
+// SetStrategy sets the Strategy, RoundRobinStrategy is used by default.
+func (p *ServiceProvider) SetStrategy(s Strategy) *ServiceProvider {
+	p.strategy = s
+	return p
+}
+
+// SetDownPolicy makes an instance down for timeout once threshold errors are
+// noted within timeout. It should not be called once the ServiceProvider is
+// used.
+func (p *ServiceProvider) SetDownPolicy(timeout time.Duration, threshold int) *ServiceProvider {
+	p.downs = newDownInstances(timeout, threshold)
+	return p
+}
+
+// SetLogger sets the Logger, the Logger of the Discovery is used by default.
+// It must be called before Start.
+func (p *ServiceProvider) SetLogger(logger enhanced.Logger) *ServiceProvider {
+	p.logger = logger
+	p.cache.SetLogger(logger)
+	return p
+}
+
+// Start starts watching instances, it blocks until existing instances are
+// loaded.
+func (p *ServiceProvider) Start() error {
+	return p.StartCtx(context.Background())
+}
+
+// StartCtx is Start with a context to stop waiting for instances to be
+// loaded, the ServiceProvider is closed then.
+func (p *ServiceProvider) StartCtx(ctx context.Context) error {
+	var err = p.cache.StartCtx(ctx, children.StartModeBuildInitial)
+	if err == children.ErrAlreadyStarted {
+		err = ErrAlreadyStarted
+	}
+	return err
+}
+
+// Close stops watching instances.
+func (p *ServiceProvider) Close() {
+	p.cache.Stop()
+}
+
+// Instances returns all instances including those down.
+func (p *ServiceProvider) Instances() []*Instance {
+	var current = p.cache.CurrentData()
+	var instances = make([]*Instance, 0, len(current))
+	for _, data := range current {
+		var i, err = decodeInstance(data.Data())
+		if err != nil {
+			p.logger.Warn("decoding service instance", "path", data.Path(), "err", err)
+			continue
+		}
+		instances = append(instances, i)
+	}
+	return instances
+}
+
+// Instance returns an instance picked by the Strategy among those not down.
+// ErrNoInstance is returned if there is no such instance.
+func (p *ServiceProvider) Instance() (*Instance, error) {
+	var instances = p.downs.filter(p.Instances(), time.Now())
+	if len(instances) == 0 {
+		return nil, ErrNoInstance
+	}
+	return p.strategy.Pick(instances), nil
+}
+
+// NoteError reports an error of i, which is down once errors reach the
+// threshold.
+func (p *ServiceProvider) NoteError(i *Instance) {
+	p.logger.Warn("service instance error noted", "path", p.path, "id", i.ID)
+	p.downs.add(i.ID, time.Now())
+}
@@ -0,0 +1,33 @@
+package discovery
+
+import "sync"
+
+// StickyStrategy keeps picking the same instance as long as it's available,
+// another one is picked by the underlying Strategy otherwise.
+type StickyStrategy struct {
+	strategy Strategy
+
+	lock   sync.Mutex
+	picked *Instance
+}
+
+// NewStickyStrategy creates a StickyStrategy picking new instances by s.
+func NewStickyStrategy(s Strategy) *StickyStrategy {
+	return &StickyStrategy{strategy: s}
+}
+
+// Pick implements Strategy.
+func (s *StickyStrategy) Pick(instances []*Instance) *Instance {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.picked != nil {
+		for _, i := range instances {
+			if i.ID == s.picked.ID {
+				s.picked = i
+				return i
+			}
+		}
+	}
+	s.picked = s.strategy.Pick(instances)
+	return s.picked
+}
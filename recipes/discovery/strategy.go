@@ -0,0 +1,7 @@
+package discovery
+
+// Strategy picks an instance for a ServiceProvider.
+// Pick is called concurrently with a non-empty list of instances.
+type Strategy interface {
+	Pick(instances []*Instance) *Instance
+}
@@ -0,0 +1,52 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testInstances() []*Instance {
+	return []*Instance{
+		{ID: "a"},
+		{ID: "b"},
+		{ID: "c"},
+	}
+}
+
+func TestRoundRobinStrategy(t *testing.T) {
+	var instances = testInstances()
+	var s = NewRoundRobinStrategy()
+	for i := 0; i < 6; i++ {
+		assert.Equal(t, instances[i%3], s.Pick(instances))
+	}
+}
+
+func TestRandomStrategy(t *testing.T) {
+	var instances = testInstances()
+	var s = NewRandomStrategy()
+	for i := 0; i < 10; i++ {
+		assert.Contains(t, instances, s.Pick(instances))
+	}
+}
+
+func TestStickyStrategy(t *testing.T) {
+	var instances = testInstances()
+	var s = NewStickyStrategy(NewRoundRobinStrategy())
+	assert.Equal(t, "a", s.Pick(instances).ID)
+	assert.Equal(t, "a", s.Pick(instances).ID)
+	assert.Equal(t, "c", s.Pick(instances[1:]).ID)
+	assert.Equal(t, "c", s.Pick(instances).ID)
+}
+
+func TestDownInstances(t *testing.T) {
+	var instances = testInstances()
+	var now = time.Now()
+	var downs = newDownInstances(time.Second, 2)
+	downs.add("a", now)
+	assert.Len(t, downs.filter(instances, now), 3)
+	downs.add("a", now)
+	assert.Equal(t, instances[1:], downs.filter(instances, now))
+	assert.Len(t, downs.filter(instances, now.Add(time.Second)), 3)
+}
@@ -0,0 +1,162 @@
+// Package znodes contains helpers of znode operations shared by recipes.
+package znodes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+)
+
+const (
+	// sequenceLen is the length of the sequence suffix of sequential znodes.
+	sequenceLen = 10
+	// deleteRetryInterval is the time to wait before retrying Delete.
+	deleteRetryInterval = time.Second
+)
+
+// ErrNotSequential indicates a znode name has no sequence suffix.
+var ErrNotSequential = errors.New("not a sequential znode")
+
+// CreateMulti creates znodes with values in mode atomically within a Tx, the
+// paths created are returned in order.
+func CreateMulti(client *enhanced.Client, paths []string, values [][]byte, mode enhanced.CreateMode) ([]string, error) {
+	var tx = client.Tx()
+	for i, p := range paths {
+		tx.Create(p, values[i], enhanced.WithMode(mode))
+	}
+	var results, err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	var created = make([]string, len(results))
+	for i, res := range results {
+		created[i] = res.Path
+	}
+	return created, nil
+}
+
+// EnsurePath creates p and its parents if missing.
+func EnsurePath(client *enhanced.Client, p string) error {
+	var _, err = client.CreateWithParents(p)
+	if err == zk.ErrNodeExists {
+		err = nil
+	}
+	return err
+}
+
+// Delete deletes p regardless of its version, it's retried until the
+// connection is re-established so the node is not left behind.
+// It's not an error if p does not exist, or it's gone with an expired session.
+func Delete(client *enhanced.Client, p string) error {
+	var err = client.Delete(p, -1, enhanced.WithRetryPolicy(enhanced.NewRetryForever(deleteRetryInterval)))
+	switch err {
+	case zk.ErrNoNode, zk.ErrSessionExpired:
+		return nil
+	default:
+		return err
+	}
+}
+
+// Sequence returns the sequence number of a sequential znode name.
+func Sequence(name string) (int64, error) {
+	if len(name) < sequenceLen {
+		return 0, ErrNotSequential
+	}
+	var seq, err = strconv.ParseInt(name[len(name)-sequenceLen:], 10, 64)
+	if err != nil {
+		return 0, ErrNotSequential
+	}
+	return seq, nil
+}
+
+// Prefix returns the name of a sequential znode without the sequence number.
+func Prefix(name string) string {
+	if len(name) < sequenceLen {
+		return name
+	}
+	return name[:len(name)-sequenceLen]
+}
+
+// SortSequential returns names containing marker sorted by sequence number,
+// names which are not sequential are dropped.
+func SortSequential(names []string, marker string) []string {
+	var sorted = make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.Contains(name, marker) {
+			continue
+		}
+		if _, err := Sequence(name); err == nil {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Sort(bySequence(sorted))
+	return sorted
+}
+
+// bySequence sorts sequential znode names by sequence number.
+type bySequence []string
+
+func (s bySequence) Len() int      { return len(s) }
+func (s bySequence) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySequence) Less(i, j int) bool {
+	var a, _ = Sequence(s[i])
+	var b, _ = Sequence(s[j])
+	return a < b
+}
+
+// IndexOf returns the index of name in names, -1 is returned if not found.
+func IndexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// WaitForDeletion blocks until p is deleted or ctx is done, it may also
+// return early once p is changed, so callers should check again.
+// zk.ErrSessionExpired is returned if the watch is dropped due to the
+// expiry of the session.
+func WaitForDeletion(ctx context.Context, client *enhanced.Client, p string) error {
+	// The watch is dropped on return, it's never triggered if p is gone.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var done = make(chan error, 1)
+	client.WatchDataCtx(ctx, p, func(result enhanced.DataResult) {
+		if result.Err == zk.ErrNoNode {
+			done <- nil
+		} else if result.Err != nil {
+			done <- result.Err
+		}
+	}, func(evt zk.Event) {
+		if evt.Type == zk.EventNotWatching {
+			done <- zk.ErrSessionExpired
+		} else {
+			done <- nil
+		}
+	})
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewToken returns a random token.
+func NewToken() string {
+	var b = make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
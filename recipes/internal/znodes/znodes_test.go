@@ -0,0 +1,37 @@
+package znodes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence(t *testing.T) {
+	var seq, err = Sequence("_c_0123-latch-0000000042")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), seq)
+
+	assert.Equal(t, "_c_0123-latch-", Prefix("_c_0123-latch-0000000042"))
+
+	_, err = Sequence("latch")
+	assert.Equal(t, ErrNotSequential, err)
+	_, err = Sequence("latch-000000000x")
+	assert.Equal(t, ErrNotSequential, err)
+}
+
+func TestSortSequential(t *testing.T) {
+	var sorted = SortSequential([]string{
+		"_c_b-latch-0000000003",
+		"lock-0000000001",
+		"_c_a-latch-0000000002",
+		"latch-0000000010",
+		"latch",
+	}, "latch-")
+	assert.Equal(t, []string{
+		"_c_a-latch-0000000002",
+		"_c_b-latch-0000000003",
+		"latch-0000000010",
+	}, sorted)
+	assert.Equal(t, 1, IndexOf(sorted, "_c_b-latch-0000000003"))
+	assert.Equal(t, -1, IndexOf(sorted, "latch"))
+}
@@ -0,0 +1,14 @@
+package leader
+
+import "errors"
+
+var (
+	// ErrAlreadyStarted indicates Start is called more than once.
+	ErrAlreadyStarted = errors.New("already started")
+	// ErrNotStarted indicates the recipe is not started or closed already.
+	ErrNotStarted = errors.New("not started")
+	// ErrClosed indicates the recipe is closed.
+	ErrClosed = errors.New("closed")
+	// ErrNoLeader indicates there is no participant at the moment.
+	ErrNoLeader = errors.New("no leader")
+)
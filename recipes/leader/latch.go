@@ -0,0 +1,295 @@
+// Package leader contains recipes of leader election.
+package leader
+
+import (
+	"context"
+	"path"
+	"sync"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// latchNodeName is the name prefix of the nodes created by Latch.
+const latchNodeName = "latch-"
+
+// Latch selects a leader among the Latches sharing the same path.
+//
+// Every started Latch creates an ephemeral sequential node under the path,
+// whose data is the participant id. The Latch owning the lowest node is the
+// leader, others watch the node just before their own.
+//
+// The leadership is given up once the connection is SUSPENDED or LOST, since
+// another Latch might have taken it, and it's competed for again with a new
+// node after the connection is RECONNECTED.
+type Latch struct {
+	client        *enhanced.Client
+	path          string
+	id            string
+	state         State
+	listeners     *LeadershipListeners
+	stateListener *enhanced.StateChangeListener
+	logger        enhanced.Logger
+	closed        chan struct{}
+	// resetLock serializes the re-creation of our node.
+	resetLock sync.Mutex
+
+	lock          sync.Mutex
+	ourPath       string
+	hasLeadership bool
+	// leaderCh is closed once the leadership is acquired.
+	leaderCh chan struct{}
+	// cancelWatch cancels the watch on the node before ours.
+	cancelWatch context.CancelFunc
+}
+
+// NewLatch creates a Latch competing for the leadership under p with given
+// participant id.
+func NewLatch(client *enhanced.Client, p string, id string) *Latch {
+	var latch = &Latch{
+		client:    client,
+		path:      path.Join("/", p),
+		id:        id,
+		state:     StateLatent,
+		listeners: NewLeadershipListeners(),
+		logger:    enhanced.NopLogger,
+		closed:    make(chan struct{}),
+		leaderCh:  make(chan struct{}),
+	}
+	if client != nil {
+		latch.logger = client.Logger()
+	}
+	latch.stateListener = enhanced.NewStateChangeListener(latch.handleStateChange)
+	return latch
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (l *Latch) SetLogger(logger enhanced.Logger) *Latch {
+	l.logger = logger
+	return l
+}
+
+// ID returns the participant id.
+func (l *Latch) ID() string {
+	return l.id
+}
+
+// Start starts competing for the leadership.
+// The Latch keeps competing after the connection is re-established even if
+// the first attempt fails, Close should be called to give up.
+func (l *Latch) Start() error {
+	if !l.state.SetValueIf(StateLatent, StateStarted) {
+		return ErrAlreadyStarted
+	}
+	l.client.AddStateChangeListener(l.stateListener)
+	return l.reset()
+}
+
+// Close gives up the leadership and stops competing for it.
+func (l *Latch) Close() error {
+	if !l.state.SetValueIf(StateStarted, StateClosed) {
+		return ErrNotStarted
+	}
+	l.client.DelStateChangeListener(l.stateListener)
+	close(l.closed)
+
+	l.resetLock.Lock()
+	defer l.resetLock.Unlock()
+	var ourPath = l.swapOurPath("")
+	l.loseLeadership()
+	return l.deleteOurPath(ourPath)
+}
+
+// HasLeadership returns true if the leadership is held at the moment.
+func (l *Latch) HasLeadership() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.hasLeadership
+}
+
+// Await blocks until the leadership is acquired, ctx is done or the Latch is
+// closed. ErrClosed is returned if the Latch is closed first.
+func (l *Latch) Await(ctx context.Context) error {
+	l.lock.Lock()
+	var leaderCh = l.leaderCh
+	l.lock.Unlock()
+
+	select {
+	case <-l.closed:
+		return ErrClosed
+	default:
+	}
+	select {
+	case <-leaderCh:
+		return nil
+	case <-l.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AddListener adds a LeadershipListener.
+// Listeners are called with true once the leadership is acquired, and false
+// once it's lost.
+func (l *Latch) AddListener(listener *LeadershipListener) {
+	l.listeners.Add(listener)
+}
+
+// DelListener deletes a LeadershipListener.
+func (l *Latch) DelListener(listener *LeadershipListener) {
+	l.listeners.Del(listener)
+}
+
+// Participants returns all participants in order, the first one is the leader.
+func (l *Latch) Participants() ([]Participant, error) {
+	return participants(l.client, l.path, latchNodeName)
+}
+
+// Leader returns the current leader.
+// ErrNoLeader is returned if there is no participant.
+func (l *Latch) Leader() (Participant, error) {
+	return leader(l.client, l.path, latchNodeName)
+}
+
+func (l *Latch) handleStateChange(s enhanced.ConnState) {
+	switch s {
+	case enhanced.ConnStateSuspended, enhanced.ConnStateLost:
+		l.loseLeadership()
+	case enhanced.ConnStateReconnected:
+		go l.resetInBackground()
+	}
+}
+
+// reset drops our node then creates a new one to compete for the leadership.
+func (l *Latch) reset() error {
+	l.resetLock.Lock()
+	defer l.resetLock.Unlock()
+	if !l.state.EqualTo(StateStarted) {
+		return nil
+	}
+
+	l.loseLeadership()
+	if err := l.deleteOurPath(l.swapOurPath("")); err != nil {
+		return err
+	}
+	if err := znodes.EnsurePath(l.client, l.path); err != nil {
+		return err
+	}
+	var created, err = l.client.CreateValue(path.Join(l.path, latchNodeName), []byte(l.id),
+		enhanced.WithMode(enhanced.ModeEphemeral|enhanced.ModeSequential), enhanced.WithProtection())
+	if err != nil {
+		return err
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	l.lock.Lock()
+	l.ourPath = created
+	l.cancelWatch = cancel
+	l.lock.Unlock()
+	l.checkLeadership(ctx)
+	return nil
+}
+
+func (l *Latch) resetInBackground() {
+	if err := l.reset(); err != nil {
+		l.logger.Error("resetting leader latch", "path", l.path, "err", err)
+	}
+}
+
+// checkLeadership takes the leadership if our node is the lowest one,
+// otherwise the node just before ours is watched.
+func (l *Latch) checkLeadership(ctx context.Context) {
+	var children, _, err = l.client.GetChildrenCtx(ctx, l.path)
+	if err != nil {
+		if ctx.Err() == nil {
+			l.logger.Error("listing leader latch participants", "path", l.path, "err", err)
+		}
+		return
+	}
+	var sorted = znodes.SortSequential(children, latchNodeName)
+
+	l.lock.Lock()
+	var ourPath = l.ourPath
+	l.lock.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch i := znodes.IndexOf(sorted, path.Base(ourPath)); {
+	case i < 0:
+		l.logger.Warn("leader latch node is gone", "path", ourPath)
+		go l.resetInBackground()
+	case i == 0:
+		l.takeLeadership(ctx)
+	default:
+		l.client.WatchDataCtx(ctx, path.Join(l.path, sorted[i-1]), func(result enhanced.DataResult) {
+			if result.Err == zk.ErrNoNode {
+				l.checkLeadership(ctx)
+			} else if result.Err != nil && ctx.Err() == nil {
+				l.logger.Error("watching leader latch participant", "path", result.Path, "err", result.Err)
+			}
+		}, func(evt zk.Event) {
+			if evt.Type != zk.EventNotWatching {
+				l.checkLeadership(ctx)
+			}
+		})
+	}
+}
+
+// swapOurPath sets our node to p and returns the old one, the watch on the
+// node before the old one is canceled.
+func (l *Latch) swapOurPath(p string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.cancelWatch != nil {
+		l.cancelWatch()
+		l.cancelWatch = nil
+	}
+	var old = l.ourPath
+	l.ourPath = p
+	return old
+}
+
+func (l *Latch) deleteOurPath(p string) error {
+	if p == "" {
+		return nil
+	}
+	var err = l.client.Delete(p, -1)
+	if err == zk.ErrNoNode {
+		err = nil
+	}
+	return err
+}
+
+// takeLeadership takes the leadership unless ctx is canceled by then.
+func (l *Latch) takeLeadership(ctx context.Context) {
+	l.lock.Lock()
+	if l.hasLeadership || ctx.Err() != nil {
+		l.lock.Unlock()
+		return
+	}
+	l.hasLeadership = true
+	close(l.leaderCh)
+	l.lock.Unlock()
+	l.notify(true)
+}
+
+func (l *Latch) loseLeadership() {
+	l.lock.Lock()
+	if !l.hasLeadership {
+		l.lock.Unlock()
+		return
+	}
+	l.hasLeadership = false
+	l.leaderCh = make(chan struct{})
+	l.lock.Unlock()
+	l.notify(false)
+}
+
+func (l *Latch) notify(isLeader bool) {
+	l.logger.Info("leadership changed", "path", l.path, "id", l.id, "isLeader", isLeader)
+	l.listeners.Broadcast(isLeader)
+}
@@ -0,0 +1,65 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func TestLatchAwait(t *testing.T) {
+	var latch = NewLatch(nil, "/leader", "a")
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, latch.Await(ctx))
+
+	latch.state = StateStarted
+	latch.takeLeadership(context.Background())
+	assert.NoError(t, latch.Await(context.Background()))
+	assert.True(t, latch.HasLeadership())
+
+	latch.handleStateChange(enhanced.ConnStateSuspended)
+	assert.False(t, latch.HasLeadership())
+
+	close(latch.closed)
+	assert.Equal(t, ErrClosed, latch.Await(context.Background()))
+}
+
+func TestLatchElection(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var first = NewLatch(env.NewClientTimeout(time.Second*2), "/leader", "first")
+		var second = NewLatch(env.NewClientTimeout(time.Second*2), "/leader", "second")
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		var changes = make(chan bool, 2)
+		second.AddListener(NewLeadershipListener(func(isLeader bool) {
+			changes <- isLeader
+		}))
+
+		assert.NoError(t, first.Start())
+		assert.NoError(t, first.Await(ctx))
+		assert.NoError(t, second.Start())
+		assert.False(t, second.HasLeadership())
+
+		var leader, err = second.Leader()
+		assert.NoError(t, err)
+		assert.Equal(t, Participant{ID: "first", IsLeader: true}, leader)
+		participants, err := first.Participants()
+		assert.NoError(t, err)
+		assert.Len(t, participants, 2)
+
+		assert.NoError(t, first.Close())
+		assert.NoError(t, second.Await(ctx))
+		assert.True(t, <-changes)
+		assert.False(t, first.HasLeadership())
+
+		assert.NoError(t, second.Close())
+		assert.False(t, <-changes)
+		_, err = first.Leader()
+		assert.Equal(t, ErrNoLeader, err)
+	})
+}
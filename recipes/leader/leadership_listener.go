@@ -0,0 +1,16 @@
+package leader
+
+// LeadershipListener is a handler of leadership changes.
+type LeadershipListener struct {
+	fn func(bool)
+}
+
+// Handle calls the function with whether the leadership is held.
+func (l *LeadershipListener) Handle(isLeader bool) {
+	l.fn(isLeader)
+}
+
+// NewLeadershipListener creates LeadershipListener from fn.
+func NewLeadershipListener(fn func(isLeader bool)) *LeadershipListener {
+	return &LeadershipListener{fn}
+}
@@ -0,0 +1,30 @@
+package leader
+
+import "github.com/tevino/zoo/enhanced"
+
+// LeadershipListeners is a container of LeadershipListeners.
+type LeadershipListeners struct {
+	*enhanced.ListenerContainer
+}
+
+// NewLeadershipListeners creates empty LeadershipListeners.
+func NewLeadershipListeners() *LeadershipListeners {
+	return &LeadershipListeners{enhanced.NewListenerContainer()}
+}
+
+// Add adds a Listener.
+func (l *LeadershipListeners) Add(listener *LeadershipListener) {
+	l.ListenerContainer.Add(listener)
+}
+
+// Del deletes a Listener.
+func (l *LeadershipListeners) Del(listener *LeadershipListener) {
+	l.ListenerContainer.Del(listener)
+}
+
+// Broadcast calls every listener with whether the leadership is held.
+func (l *LeadershipListeners) Broadcast(isLeader bool) {
+	l.ListenerContainer.Foreach(func(listener interface{}) {
+		listener.(*LeadershipListener).Handle(isLeader)
+	})
+}
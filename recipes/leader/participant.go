@@ -0,0 +1,54 @@
+package leader
+
+import (
+	"path"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// Participant represents a member of a leader election.
+type Participant struct {
+	// ID is the participant id stored in the data of its node.
+	ID string
+	// IsLeader indicates whether the participant holds the leadership.
+	IsLeader bool
+}
+
+// participants returns members of the election under p in order,
+// the first one is the leader.
+func participants(client *enhanced.Client, p, nodeName string) ([]Participant, error) {
+	var children, _, err = client.GetChildren(p)
+	if err != nil {
+		return nil, err
+	}
+	var result []Participant
+	for _, child := range znodes.SortSequential(children, nodeName) {
+		var data, _, err = client.Get(path.Join(p, child))
+		if err == zk.ErrNoNode {
+			// The participant left in the meantime.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Participant{
+			ID:       string(data),
+			IsLeader: len(result) == 0,
+		})
+	}
+	return result, nil
+}
+
+// leader returns the leader of the election under p.
+func leader(client *enhanced.Client, p, nodeName string) (Participant, error) {
+	var all, err = participants(client, p, nodeName)
+	if err != nil {
+		return Participant{}, err
+	}
+	if len(all) == 0 {
+		return Participant{}, ErrNoLeader
+	}
+	return all[0], nil
+}
@@ -0,0 +1,245 @@
+package leader
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+const (
+	// selectorNodeName is the name prefix of the nodes created by Selector.
+	selectorNodeName = "lock-"
+	// requeueInterval is the time to wait before requeuing after a failure.
+	requeueInterval = time.Second
+)
+
+// Selector selects a leader among the Selectors sharing the same path, and
+// calls SelectorListener.TakeLeadership of the leader.
+//
+// The leadership is held until TakeLeadership returns, after which the
+// Selector competes for it again only if it's requeued. The context passed
+// to TakeLeadership is canceled once the connection is SUSPENDED or LOST,
+// since another Selector might have taken the leadership.
+type Selector struct {
+	client        *enhanced.Client
+	path          string
+	id            string
+	listener      SelectorListener
+	state         State
+	stateListener *enhanced.StateChangeListener
+	logger        enhanced.Logger
+	ctx           context.Context
+	cancel        context.CancelFunc
+
+	lock          sync.Mutex
+	autoRequeue   bool
+	queued        bool
+	hasLeadership bool
+	// cancelLeadership cancels the context of the running TakeLeadership.
+	cancelLeadership context.CancelFunc
+}
+
+// NewSelector creates a Selector competing for the leadership under p with
+// given participant id.
+func NewSelector(client *enhanced.Client, p string, id string, listener SelectorListener) *Selector {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var selector = &Selector{
+		client:   client,
+		path:     path.Join("/", p),
+		id:       id,
+		listener: listener,
+		state:    StateLatent,
+		logger:   enhanced.NopLogger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	if client != nil {
+		selector.logger = client.Logger()
+	}
+	selector.stateListener = enhanced.NewStateChangeListener(selector.handleStateChange)
+	return selector
+}
+
+// SetAutoRequeue sets whether to requeue automatically once the leadership
+// is relinquished, it's disabled by default.
+func (s *Selector) SetAutoRequeue(yes bool) *Selector {
+	s.lock.Lock()
+	s.autoRequeue = yes
+	s.lock.Unlock()
+	return s
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (s *Selector) SetLogger(logger enhanced.Logger) *Selector {
+	s.logger = logger
+	return s
+}
+
+// ID returns the participant id.
+func (s *Selector) ID() string {
+	return s.id
+}
+
+// Start starts competing for the leadership.
+func (s *Selector) Start() error {
+	if !s.state.SetValueIf(StateLatent, StateStarted) {
+		return ErrAlreadyStarted
+	}
+	s.client.AddStateChangeListener(s.stateListener)
+	s.Requeue()
+	return nil
+}
+
+// Close stops competing for the leadership, the running TakeLeadership is
+// interrupted.
+func (s *Selector) Close() error {
+	if !s.state.SetValueIf(StateStarted, StateClosed) {
+		return ErrNotStarted
+	}
+	s.client.DelStateChangeListener(s.stateListener)
+	s.cancel()
+	return nil
+}
+
+// Requeue queues the Selector to compete for the leadership again.
+// It has no effect if the Selector is queued or leading already, or it's not
+// started. The returning value indicates whether the Selector is queued.
+func (s *Selector) Requeue() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.state.EqualTo(StateStarted) || s.queued {
+		return false
+	}
+	s.queued = true
+	go s.run()
+	return true
+}
+
+// InterruptLeadership cancels the context of the running TakeLeadership.
+func (s *Selector) InterruptLeadership() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.cancelLeadership != nil {
+		s.cancelLeadership()
+	}
+}
+
+// HasLeadership returns true if TakeLeadership is running.
+func (s *Selector) HasLeadership() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.hasLeadership
+}
+
+// Participants returns all participants in order, the first one is the leader.
+func (s *Selector) Participants() ([]Participant, error) {
+	return participants(s.client, s.path, selectorNodeName)
+}
+
+// Leader returns the current leader.
+// ErrNoLeader is returned if there is no participant.
+func (s *Selector) Leader() (Participant, error) {
+	return leader(s.client, s.path, selectorNodeName)
+}
+
+func (s *Selector) handleStateChange(state enhanced.ConnState) {
+	switch state {
+	case enhanced.ConnStateSuspended, enhanced.ConnStateLost:
+		s.InterruptLeadership()
+	}
+}
+
+func (s *Selector) run() {
+	var err = s.runOnce()
+
+	s.lock.Lock()
+	s.queued = false
+	var requeue = s.autoRequeue
+	s.lock.Unlock()
+
+	if err != nil && s.ctx.Err() == nil {
+		s.logger.Error("selecting leader", "path", s.path, "id", s.id, "err", err)
+		// Wait a while to avoid competing in a busy loop.
+		select {
+		case <-time.After(requeueInterval):
+		case <-s.ctx.Done():
+		}
+	}
+	if requeue {
+		s.Requeue()
+	}
+}
+
+// runOnce waits for the leadership then calls TakeLeadership.
+func (s *Selector) runOnce() error {
+	var ourPath, err = s.waitForLeadership()
+	if ourPath != "" {
+		defer s.deleteNode(ourPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	var ctx, cancel = context.WithCancel(s.ctx)
+	defer cancel()
+	s.setLeadership(true, cancel)
+	defer s.setLeadership(false, nil)
+	if !s.client.ConnState().IsConnected() {
+		// The connection is suspended before the leadership is taken.
+		cancel()
+	}
+	s.logger.Info("leadership taken", "path", s.path, "id", s.id)
+	s.listener.TakeLeadership(ctx)
+	s.logger.Info("leadership relinquished", "path", s.path, "id", s.id)
+	return nil
+}
+
+// waitForLeadership creates our node and blocks until it's the lowest one.
+// The node created is returned even if an error occurs.
+func (s *Selector) waitForLeadership() (string, error) {
+	if err := znodes.EnsurePath(s.client, s.path); err != nil {
+		return "", err
+	}
+	var ourPath, err = s.client.CreateValue(path.Join(s.path, selectorNodeName), []byte(s.id),
+		enhanced.WithMode(enhanced.ModeEphemeral|enhanced.ModeSequential), enhanced.WithProtection())
+	if err != nil {
+		return "", err
+	}
+	for {
+		var children, _, err = s.client.GetChildrenCtx(s.ctx, s.path)
+		if err != nil {
+			return ourPath, err
+		}
+		var sorted = znodes.SortSequential(children, selectorNodeName)
+		switch i := znodes.IndexOf(sorted, path.Base(ourPath)); {
+		case i < 0:
+			return ourPath, zk.ErrNoNode
+		case i == 0:
+			return ourPath, nil
+		default:
+			if err := znodes.WaitForDeletion(s.ctx, s.client, path.Join(s.path, sorted[i-1])); err != nil {
+				return ourPath, err
+			}
+		}
+	}
+}
+
+// deleteNode deletes p so it's not left to block others.
+func (s *Selector) deleteNode(p string) {
+	if err := znodes.Delete(s.client, p); err != nil {
+		s.logger.Error("deleting leader selector node", "path", p, "err", err)
+	}
+}
+
+func (s *Selector) setLeadership(isLeader bool, cancel context.CancelFunc) {
+	s.lock.Lock()
+	s.hasLeadership = isLeader
+	s.cancelLeadership = cancel
+	s.lock.Unlock()
+}
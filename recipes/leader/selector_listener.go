@@ -0,0 +1,20 @@
+package leader
+
+import "context"
+
+// SelectorListener is notified when a Selector takes the leadership.
+type SelectorListener interface {
+	// TakeLeadership is called once the leadership is acquired, it should not
+	// return until the leadership is to be relinquished.
+	// ctx is canceled once the leadership is lost, interrupted or the
+	// Selector is closed, TakeLeadership should return ASAP by then.
+	TakeLeadership(ctx context.Context)
+}
+
+// SelectorListenerFunc is an adapter to use a function as SelectorListener.
+type SelectorListenerFunc func(ctx context.Context)
+
+// TakeLeadership implements SelectorListener.
+func (f SelectorListenerFunc) TakeLeadership(ctx context.Context) {
+	f(ctx)
+}
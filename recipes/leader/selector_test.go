@@ -0,0 +1,70 @@
+package leader
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestSelectorRequeue(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var leading, overlapped int32
+		var taken = make(chan string, 10)
+		var newSelector = func(id string) *Selector {
+			var client = env.NewClientTimeout(time.Second * 2).SetNamespace("/ns")
+			return NewSelector(client, "/selector", id, SelectorListenerFunc(func(ctx context.Context) {
+				if atomic.AddInt32(&leading, 1) > 1 {
+					atomic.StoreInt32(&overlapped, 1)
+				}
+				time.Sleep(time.Millisecond * 100)
+				atomic.AddInt32(&leading, -1)
+				taken <- id
+			})).SetAutoRequeue(true)
+		}
+		var a, b = newSelector("a"), newSelector("b")
+		assert.NoError(t, a.Start())
+		assert.NoError(t, b.Start())
+
+		var seen = make(map[string]bool)
+		for len(seen) < 2 {
+			select {
+			case id := <-taken:
+				seen[id] = true
+			case <-time.After(time.Second * 5):
+				t.Fatal("Waiting for leadership timed out")
+			}
+		}
+		assert.NoError(t, a.Close())
+		assert.NoError(t, b.Close())
+		assert.Equal(t, int32(0), atomic.LoadInt32(&overlapped))
+		env.AssertZNode("/ns/selector")
+	})
+}
+
+func TestSelectorInterrupt(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var taken = make(chan struct{})
+		var relinquished = make(chan struct{})
+		var selector = NewSelector(env.NewClientTimeout(time.Second*2), "/selector", "a",
+			SelectorListenerFunc(func(ctx context.Context) {
+				close(taken)
+				<-ctx.Done()
+				close(relinquished)
+			}))
+		assert.NoError(t, selector.Start())
+		<-taken
+		assert.True(t, selector.HasLeadership())
+		var leader, err = selector.Leader()
+		assert.NoError(t, err)
+		assert.Equal(t, "a", leader.ID)
+
+		selector.InterruptLeadership()
+		<-relinquished
+		assert.NoError(t, selector.Close())
+		assert.False(t, selector.Requeue())
+	})
+}
@@ -0,0 +1,30 @@
+package leader
+
+import "sync/atomic"
+
+const (
+	// StateLatent indicates Start has not yet been called.
+	StateLatent State = iota
+	// StateStarted indicates Start has been called.
+	StateStarted
+	// StateClosed indicates Close has been called.
+	StateClosed
+)
+
+// State represents the state of a leader recipe.
+type State int32
+
+// SetValueIf does a CAS operation.
+func (s *State) SetValueIf(old, new State) bool {
+	return atomic.CompareAndSwapInt32((*int32)(s), int32(old), int32(new))
+}
+
+// Value returns the state.
+func (s *State) Value() State {
+	return State(atomic.LoadInt32((*int32)(s)))
+}
+
+// EqualTo returns true if value equals to given State.
+func (s *State) EqualTo(x State) bool {
+	return s.Value() == x
+}
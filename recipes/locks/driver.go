@@ -0,0 +1,61 @@
+package locks
+
+import (
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// driver decides which node holds a lock.
+type driver interface {
+	// getsTheLock returns true if the node named ourName holds the lock among
+	// children, otherwise the name of the node to watch is returned.
+	// zk.ErrNoNode is returned if ourName is not one of children.
+	getsTheLock(children []string, ourName string) (bool, string, error)
+}
+
+// standardDriver grants the lock to the first maxLeases nodes named with
+// the same prefix, nodes of all names are taken into account if the prefix
+// is empty.
+type standardDriver struct {
+	name      string
+	maxLeases int
+}
+
+func (d standardDriver) getsTheLock(children []string, ourName string) (bool, string, error) {
+	var sorted = znodes.SortSequential(children, d.name)
+	var i = znodes.IndexOf(sorted, ourName)
+	if i < 0 {
+		return false, "", zk.ErrNoNode
+	}
+	if i < d.maxLeases {
+		return true, "", nil
+	}
+	return false, sorted[i-d.maxLeases], nil
+}
+
+// readDriver grants the lock to a reader unless a writer is ahead of it,
+// the nearest writer ahead is watched.
+type readDriver struct {
+	// writer is the write lock of the same owner, the read lock is granted
+	// if it's held.
+	writer *Mutex
+}
+
+func (d readDriver) getsTheLock(children []string, ourName string) (bool, string, error) {
+	var sorted = znodes.SortSequential(children, "")
+	var i = znodes.IndexOf(sorted, ourName)
+	if i < 0 {
+		return false, "", zk.ErrNoNode
+	}
+	if d.writer.IsLocked() {
+		return true, "", nil
+	}
+	for j := i - 1; j >= 0; j-- {
+		if strings.Contains(sorted[j], writeNodeName) {
+			return false, sorted[j], nil
+		}
+	}
+	return true, "", nil
+}
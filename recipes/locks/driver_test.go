@@ -0,0 +1,64 @@
+package locks
+
+import (
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandardDriver(t *testing.T) {
+	var children = []string{
+		"_c_b-lock-0000000002",
+		"_c_a-lock-0000000001",
+		"_c_c-lock-0000000003",
+	}
+	var d = standardDriver{"lock-", 1}
+
+	locked, watch, err := d.getsTheLock(children, "_c_a-lock-0000000001")
+	assert.NoError(t, err)
+	assert.True(t, locked)
+	assert.Empty(t, watch)
+
+	locked, watch, err = d.getsTheLock(children, "_c_c-lock-0000000003")
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, "_c_b-lock-0000000002", watch)
+
+	_, _, err = d.getsTheLock(children, "_c_d-lock-0000000004")
+	assert.Equal(t, zk.ErrNoNode, err)
+
+	d.maxLeases = 2
+	locked, _, _ = d.getsTheLock(children, "_c_b-lock-0000000002")
+	assert.True(t, locked)
+	_, watch, _ = d.getsTheLock(children, "_c_c-lock-0000000003")
+	assert.Equal(t, "_c_a-lock-0000000001", watch)
+}
+
+func TestReadDriver(t *testing.T) {
+	var children = []string{
+		"_c_a-__READ__0000000001",
+		"_c_b-__WRIT__0000000002",
+		"_c_c-__READ__0000000003",
+		"_c_d-__READ__0000000004",
+	}
+	var d = readDriver{NewMutex(nil, "/lock")}
+
+	locked, _, err := d.getsTheLock(children, "_c_a-__READ__0000000001")
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	locked, watch, err := d.getsTheLock(children, "_c_d-__READ__0000000004")
+	assert.NoError(t, err)
+	assert.False(t, locked)
+	assert.Equal(t, "_c_b-__WRIT__0000000002", watch)
+
+	// The write lock of the same owner is held.
+	d.writer.count = 1
+	locked, _, _ = d.getsTheLock(children, "_c_c-__READ__0000000003")
+	assert.True(t, locked)
+
+	var w = standardDriver{"", 1}
+	_, watch, _ = w.getsTheLock(children, "_c_b-__WRIT__0000000002")
+	assert.Equal(t, "_c_a-__READ__0000000001", watch)
+}
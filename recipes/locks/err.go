@@ -0,0 +1,10 @@
+package locks
+
+import "errors"
+
+var (
+	// ErrNotLocked indicates the lock is not held.
+	ErrNotLocked = errors.New("not locked")
+	// ErrSessionLost indicates the session is lost while acquiring the lock.
+	ErrSessionLost = errors.New("session lost while acquiring the lock")
+)
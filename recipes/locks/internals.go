@@ -0,0 +1,94 @@
+package locks
+
+import (
+	"context"
+	"path"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// lockInternals acquires locks by creating ephemeral sequential nodes under
+// a path, the node which holds a lock is decided by a driver.
+type lockInternals struct {
+	client *enhanced.Client
+	path   string
+	name   string
+	driver driver
+}
+
+func newLockInternals(client *enhanced.Client, p string, name string, d driver) *lockInternals {
+	return &lockInternals{
+		client: client,
+		path:   path.Join("/", p),
+		name:   name,
+		driver: d,
+	}
+}
+
+// attempt creates a node with data then blocks until it holds the lock.
+// The node is deleted if the lock is not acquired.
+func (li *lockInternals) attempt(ctx context.Context, data []byte) (string, error) {
+	if err := znodes.EnsurePath(li.client, li.path); err != nil {
+		return "", err
+	}
+	var ourPath, err = li.client.CreateValue(path.Join(li.path, li.name), data,
+		enhanced.WithMode(enhanced.ModeEphemeral|enhanced.ModeSequential), enhanced.WithProtection())
+	if err != nil {
+		return "", err
+	}
+	if err = li.waitForLock(ctx, ourPath); err != nil {
+		if delErr := li.release(ourPath); delErr != nil {
+			li.client.Logger().Error("deleting lock node", "path", ourPath, "err", delErr)
+		}
+		return "", err
+	}
+	return ourPath, nil
+}
+
+// waitForLock blocks until ourPath holds the lock, only the node decided by
+// the driver is watched in the meantime.
+func (li *lockInternals) waitForLock(ctx context.Context, ourPath string) error {
+	for {
+		var children, _, err = li.client.GetChildrenCtx(ctx, li.path)
+		if err != nil {
+			return err
+		}
+		locked, watchName, err := li.driver.getsTheLock(children, path.Base(ourPath))
+		if err != nil || locked {
+			return err
+		}
+		if err = znodes.WaitForDeletion(ctx, li.client, path.Join(li.path, watchName)); err != nil {
+			return err
+		}
+	}
+}
+
+// release deletes the node of a lock.
+func (li *lockInternals) release(ourPath string) error {
+	return znodes.Delete(li.client, ourPath)
+}
+
+// participants returns the data of nodes named with prefix in order.
+func (li *lockInternals) participants(prefix string) ([]string, error) {
+	var children, _, err = li.client.GetChildren(li.path)
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, child := range znodes.SortSequential(children, prefix) {
+		var data, _, err = li.client.Get(path.Join(li.path, child))
+		if err == zk.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, string(data))
+	}
+	return result, nil
+}
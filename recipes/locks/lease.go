@@ -0,0 +1,33 @@
+package locks
+
+import (
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// Lease is a lease acquired from a Semaphore.
+type Lease struct {
+	client *enhanced.Client
+	path   string
+}
+
+// Path returns the path of the lease node.
+func (l *Lease) Path() string {
+	return l.path
+}
+
+// Return returns the lease to the Semaphore.
+func (l *Lease) Return() error {
+	return znodes.Delete(l.client, l.path)
+}
+
+// ReturnAll returns all leases, the first error is returned.
+func ReturnAll(leases []*Lease) error {
+	var firstErr error
+	for _, lease := range leases {
+		if err := lease.Return(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,16 @@
+package locks
+
+// LostListener is a handler of lock losses.
+type LostListener struct {
+	fn func(string)
+}
+
+// Handle calls the function with the path of the lost lock node.
+func (l *LostListener) Handle(p string) {
+	l.fn(p)
+}
+
+// NewLostListener creates LostListener from fn.
+func NewLostListener(fn func(p string)) *LostListener {
+	return &LostListener{fn}
+}
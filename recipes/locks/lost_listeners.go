@@ -0,0 +1,30 @@
+package locks
+
+import "github.com/tevino/zoo/enhanced"
+
+// LostListeners is a container of LostListeners.
+type LostListeners struct {
+	*enhanced.ListenerContainer
+}
+
+// NewLostListeners creates empty LostListeners.
+func NewLostListeners() *LostListeners {
+	return &LostListeners{enhanced.NewListenerContainer()}
+}
+
+// Add adds a Listener.
+func (l *LostListeners) Add(listener *LostListener) {
+	l.ListenerContainer.Add(listener)
+}
+
+// Del deletes a Listener.
+func (l *LostListeners) Del(listener *LostListener) {
+	l.ListenerContainer.Del(listener)
+}
+
+// Broadcast calls every listener with the path of the lost lock node.
+func (l *LostListeners) Broadcast(p string) {
+	l.ListenerContainer.Foreach(func(listener interface{}) {
+		listener.(*LostListener).Handle(p)
+	})
+}
@@ -0,0 +1,187 @@
+// Package locks contains recipes of distributed locks.
+package locks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// mutexNodeName is the name prefix of the nodes created by Mutex.
+const mutexNodeName = "lock-"
+
+// Mutex is a reentrant lock shared by processes.
+//
+// Every attempt creates an ephemeral sequential node under the path, whose
+// data is the owner token. The lowest node holds the lock, others watch the
+// node just before their own only, so releasing the lock wakes up one waiter.
+//
+// A Mutex represents one owner, calling Lock on a Mutex which holds the lock
+// returns immediately, and the lock is released after the same number of
+// Unlock. Use a Mutex per goroutine if they should exclude each other.
+//
+// The lock is lost once the session expires, LostListeners are notified then.
+type Mutex struct {
+	internals     *lockInternals
+	owner         string
+	lostListeners *LostListeners
+	stateListener *enhanced.StateChangeListener
+	// acquiring serializes attempts of acquiring the lock.
+	acquiring chan struct{}
+
+	lock    sync.Mutex
+	ourPath string
+	count   int
+	// lostWhileAcquiring indicates the session is lost during an attempt.
+	lostWhileAcquiring bool
+}
+
+// NewMutex creates a Mutex locking p with a random owner token.
+func NewMutex(client *enhanced.Client, p string) *Mutex {
+	return newMutex(client, p, mutexNodeName, standardDriver{mutexNodeName, 1})
+}
+
+func newMutex(client *enhanced.Client, p string, name string, d driver) *Mutex {
+	var m = &Mutex{
+		internals:     newLockInternals(client, p, name, d),
+		owner:         znodes.NewToken(),
+		lostListeners: NewLostListeners(),
+		acquiring:     make(chan struct{}, 1),
+	}
+	m.stateListener = enhanced.NewStateChangeListener(m.handleStateChange)
+	return m
+}
+
+// SetOwner sets the owner token stored in the lock node.
+// It should not be called once the Mutex is used.
+func (m *Mutex) SetOwner(owner string) *Mutex {
+	m.owner = owner
+	return m
+}
+
+// Owner returns the owner token.
+func (m *Mutex) Owner() string {
+	return m.owner
+}
+
+// Lock blocks until the lock is acquired or ctx is done.
+// The error of ctx is returned if ctx is done first.
+func (m *Mutex) Lock(ctx context.Context) error {
+	select {
+	case m.acquiring <- struct{}{}:
+		defer func() { <-m.acquiring }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	m.lock.Lock()
+	if m.count > 0 {
+		m.count++
+		m.lock.Unlock()
+		return nil
+	}
+	m.lostWhileAcquiring = false
+	m.lock.Unlock()
+
+	// Listen before attempting so that the session lost right after acquiring
+	// is noticed.
+	m.internals.client.AddStateChangeListener(m.stateListener)
+	var ourPath, err = m.internals.attempt(ctx, []byte(m.owner))
+	if err != nil {
+		m.internals.client.DelStateChangeListener(m.stateListener)
+		return err
+	}
+	m.lock.Lock()
+	if m.lostWhileAcquiring {
+		m.lock.Unlock()
+		m.internals.client.DelStateChangeListener(m.stateListener)
+		// The node is gone along with the session, deleting it just in case.
+		if err = m.internals.release(ourPath); err != nil {
+			m.internals.client.Logger().Error("deleting lock node", "path", ourPath, "err", err)
+		}
+		return ErrSessionLost
+	}
+	m.ourPath = ourPath
+	m.count = 1
+	m.lock.Unlock()
+	return nil
+}
+
+// TryLock tries to acquire the lock within timeout.
+// The returning value indicates whether the lock is acquired.
+func (m *Mutex) TryLock(timeout time.Duration) (bool, error) {
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var err = m.Lock(ctx)
+	if err == context.DeadlineExceeded {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Unlock releases the lock once it's called as many times as Lock.
+// ErrNotLocked is returned if the lock is not held, e.g. it has been lost.
+func (m *Mutex) Unlock() error {
+	m.lock.Lock()
+	if m.count == 0 {
+		m.lock.Unlock()
+		return ErrNotLocked
+	}
+	m.count--
+	if m.count > 0 {
+		m.lock.Unlock()
+		return nil
+	}
+	var ourPath = m.ourPath
+	m.ourPath = ""
+	m.lock.Unlock()
+
+	m.internals.client.DelStateChangeListener(m.stateListener)
+	return m.internals.release(ourPath)
+}
+
+// IsLocked returns true if the lock is held by this Mutex.
+func (m *Mutex) IsLocked() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.count > 0
+}
+
+// Participants returns owner tokens of the holder and waiters in order.
+func (m *Mutex) Participants() ([]string, error) {
+	return m.internals.participants(m.internals.name)
+}
+
+// AddLostListener adds a LostListener.
+// Listeners are called once the lock held is lost due to session expiry.
+func (m *Mutex) AddLostListener(listener *LostListener) {
+	m.lostListeners.Add(listener)
+}
+
+// DelLostListener deletes a LostListener.
+func (m *Mutex) DelLostListener(listener *LostListener) {
+	m.lostListeners.Del(listener)
+}
+
+func (m *Mutex) handleStateChange(s enhanced.ConnState) {
+	if s != enhanced.ConnStateLost {
+		return
+	}
+	m.lock.Lock()
+	var ourPath = m.ourPath
+	m.ourPath = ""
+	m.count = 0
+	if ourPath == "" {
+		m.lostWhileAcquiring = true
+		m.lock.Unlock()
+		return
+	}
+	m.lock.Unlock()
+
+	m.internals.client.DelStateChangeListener(m.stateListener)
+	m.internals.client.Logger().Warn("lock lost", "path", ourPath, "owner", m.owner)
+	m.lostListeners.Broadcast(ourPath)
+}
@@ -0,0 +1,98 @@
+package locks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestMutex(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var a = NewMutex(env.NewClientTimeout(time.Second*2), "/lock").SetOwner("a")
+		var b = NewMutex(env.NewClientTimeout(time.Second*2), "/lock").SetOwner("b")
+		var ctx = context.Background()
+
+		assert.NoError(t, a.Lock(ctx))
+		// Reentrant.
+		assert.NoError(t, a.Lock(ctx))
+		assert.True(t, a.IsLocked())
+
+		locked, err := b.TryLock(time.Millisecond * 100)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+
+		var acquired = make(chan error, 1)
+		go func() {
+			acquired <- b.Lock(ctx)
+		}()
+		time.Sleep(time.Millisecond * 100)
+		participants, err := a.Participants()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, participants)
+
+		assert.NoError(t, a.Unlock())
+		select {
+		case <-acquired:
+			t.Fatal("Lock acquired before fully unlocked")
+		case <-time.After(time.Millisecond * 100):
+		}
+		assert.NoError(t, a.Unlock())
+		assert.Equal(t, ErrNotLocked, a.Unlock())
+
+		select {
+		case err := <-acquired:
+			assert.NoError(t, err)
+		case <-time.After(time.Second * 5):
+			t.Fatal("Waiting for lock timed out")
+		}
+		assert.NoError(t, b.Unlock())
+	})
+}
+
+func TestMutexSessionLostWhileAcquiring(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var a = NewMutex(env.NewClientTimeout(time.Second*2), "/lock").SetOwner("a")
+		var client, proxy = env.NewProxiedClientTimeout(time.Second * 2)
+		var b = NewMutex(client, "/lock").SetOwner("b")
+		var lost = make(chan string, 1)
+		b.AddLostListener(NewLostListener(func(p string) {
+			lost <- p
+		}))
+		var ctx = context.Background()
+		assert.NoError(t, a.Lock(ctx))
+
+		var acquired = make(chan error, 1)
+		go func() {
+			acquired <- b.Lock(ctx)
+		}()
+		time.Sleep(time.Millisecond * 100)
+		proxy.ExpireSession()
+		select {
+		case err := <-acquired:
+			assert.Error(t, err)
+		case <-time.After(time.Second * 10):
+			t.Fatal("Waiting for lock timed out")
+		}
+		assert.False(t, b.IsLocked())
+
+		// Acquirable within the new session, and the loss is noticed.
+		assert.NoError(t, a.Unlock())
+		assert.True(t, client.BlockUntilConnected(time.Second*5))
+		assert.NoError(t, b.Lock(ctx))
+		select {
+		case p := <-lost:
+			t.Fatalf("Unexpected loss of %s", p)
+		default:
+		}
+		proxy.ExpireSession()
+		select {
+		case <-lost:
+		case <-time.After(time.Second * 10):
+			t.Fatal("Waiting for lock loss timed out")
+		}
+		assert.False(t, b.IsLocked())
+	})
+}
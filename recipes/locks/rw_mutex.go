@@ -0,0 +1,93 @@
+package locks
+
+import (
+	"context"
+	"time"
+
+	"github.com/tevino/zoo/enhanced"
+)
+
+const (
+	// readNodeName is the name prefix of the nodes created by readers.
+	readNodeName = "__READ__"
+	// writeNodeName is the name prefix of the nodes created by writers.
+	writeNodeName = "__WRIT__"
+)
+
+// RWMutex is a reentrant reader/writer lock shared by processes.
+//
+// Readers share the lock while a writer holds it exclusively. Attempts are
+// served in order, so a reader arriving after a waiting writer waits until
+// the writer releases the lock, which prevents writers from starving.
+// Readers watch the nearest writer ahead only, writers watch the node just
+// before their own.
+//
+// The write lock can be downgraded by acquiring the read lock before
+// releasing the write lock, while upgrading the read lock blocks forever.
+type RWMutex struct {
+	writer *Mutex
+	reader *Mutex
+}
+
+// NewRWMutex creates a RWMutex locking p with a random owner token.
+func NewRWMutex(client *enhanced.Client, p string) *RWMutex {
+	var rw = &RWMutex{
+		writer: newMutex(client, p, writeNodeName, standardDriver{"", 1}),
+	}
+	rw.reader = newMutex(client, p, readNodeName, readDriver{rw.writer})
+	rw.reader.SetOwner(rw.writer.Owner())
+	return rw
+}
+
+// SetOwner sets the owner token stored in the lock nodes.
+// It should not be called once the RWMutex is used.
+func (rw *RWMutex) SetOwner(owner string) *RWMutex {
+	rw.writer.SetOwner(owner)
+	rw.reader.SetOwner(owner)
+	return rw
+}
+
+// Owner returns the owner token.
+func (rw *RWMutex) Owner() string {
+	return rw.writer.Owner()
+}
+
+// Writer returns the write lock.
+func (rw *RWMutex) Writer() *Mutex {
+	return rw.writer
+}
+
+// Reader returns the read lock.
+func (rw *RWMutex) Reader() *Mutex {
+	return rw.reader
+}
+
+// Lock blocks until the write lock is acquired or ctx is done.
+func (rw *RWMutex) Lock(ctx context.Context) error {
+	return rw.writer.Lock(ctx)
+}
+
+// TryLock tries to acquire the write lock within timeout.
+func (rw *RWMutex) TryLock(timeout time.Duration) (bool, error) {
+	return rw.writer.TryLock(timeout)
+}
+
+// Unlock releases the write lock.
+func (rw *RWMutex) Unlock() error {
+	return rw.writer.Unlock()
+}
+
+// RLock blocks until the read lock is acquired or ctx is done.
+func (rw *RWMutex) RLock(ctx context.Context) error {
+	return rw.reader.Lock(ctx)
+}
+
+// TryRLock tries to acquire the read lock within timeout.
+func (rw *RWMutex) TryRLock(timeout time.Duration) (bool, error) {
+	return rw.reader.TryLock(timeout)
+}
+
+// RUnlock releases the read lock.
+func (rw *RWMutex) RUnlock() error {
+	return rw.reader.Unlock()
+}
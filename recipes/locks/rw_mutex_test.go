@@ -0,0 +1,45 @@
+package locks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestRWMutex(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var a = NewRWMutex(env.NewClientTimeout(time.Second*2), "/rwlock")
+		var b = NewRWMutex(env.NewClientTimeout(time.Second*2), "/rwlock")
+		var ctx = context.Background()
+
+		// Readers share the lock.
+		assert.NoError(t, a.RLock(ctx))
+		locked, err := b.TryRLock(time.Second)
+		assert.NoError(t, err)
+		assert.True(t, locked)
+		assert.NoError(t, b.RUnlock())
+
+		locked, err = b.TryLock(time.Millisecond * 100)
+		assert.NoError(t, err)
+		assert.False(t, locked)
+		assert.NoError(t, a.RUnlock())
+
+		// Downgrade.
+		assert.NoError(t, a.Lock(ctx))
+		assert.NoError(t, a.RLock(ctx))
+		assert.NoError(t, a.Unlock())
+		locked, _ = b.TryLock(time.Millisecond * 100)
+		assert.False(t, locked)
+		locked, _ = b.TryRLock(time.Second)
+		assert.True(t, locked)
+		assert.NoError(t, b.RUnlock())
+		assert.NoError(t, a.RUnlock())
+
+		locked, _ = b.TryLock(time.Second)
+		assert.True(t, locked)
+		assert.NoError(t, b.Unlock())
+	})
+}
@@ -0,0 +1,227 @@
+package locks
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+const (
+	// leaseNodeName is the name prefix of the nodes created by Semaphore.
+	leaseNodeName = "lease-"
+	// semaphoreLockDir is the directory of the lock serializing waiters.
+	semaphoreLockDir = "locks"
+	// semaphoreLeaseDir is the directory of lease nodes.
+	semaphoreLeaseDir = "leases"
+)
+
+// Semaphore hands out a limited number of leases shared by processes.
+//
+// Every lease is an ephemeral sequential node, the first N of which are
+// granted. Waiters are serialized by a Mutex, so only one of them watches
+// the lease nodes at a time.
+//
+// The number of leases is either fixed or stored in a znode as a decimal
+// integer, in which case it can be changed at runtime. All Semaphores
+// sharing the path must agree on the number.
+type Semaphore struct {
+	client    *enhanced.Client
+	leasePath string
+	mutex     *Mutex
+	owner     string
+	maxLeases int
+	countPath string
+}
+
+// NewSemaphore creates a Semaphore under p handing out maxLeases leases.
+func NewSemaphore(client *enhanced.Client, p string, maxLeases int) *Semaphore {
+	return &Semaphore{
+		client:    client,
+		leasePath: path.Join("/", p, semaphoreLeaseDir),
+		mutex:     NewMutex(client, path.Join(p, semaphoreLockDir)),
+		owner:     znodes.NewToken(),
+		maxLeases: maxLeases,
+	}
+}
+
+// NewSharedSemaphore creates a Semaphore under p whose number of leases is
+// stored in countPath, there is no lease if countPath does not exist.
+func NewSharedSemaphore(client *enhanced.Client, p string, countPath string) *Semaphore {
+	var s = NewSemaphore(client, p, 0)
+	s.countPath = path.Join("/", countPath)
+	return s
+}
+
+// SetOwner sets the owner token stored in lease nodes.
+// It should not be called once the Semaphore is used.
+func (s *Semaphore) SetOwner(owner string) *Semaphore {
+	s.owner = owner
+	s.mutex.SetOwner(owner)
+	return s
+}
+
+// Owner returns the owner token.
+func (s *Semaphore) Owner() string {
+	return s.owner
+}
+
+// Acquire blocks until n leases are acquired or ctx is done.
+// No lease is held if an error is returned.
+func (s *Semaphore) Acquire(ctx context.Context, n int) ([]*Lease, error) {
+	var leases = make([]*Lease, 0, n)
+	for i := 0; i < n; i++ {
+		var lease, err = s.acquireOne(ctx)
+		if err != nil {
+			if retErr := ReturnAll(leases); retErr != nil {
+				s.client.Logger().Error("returning leases", "path", s.leasePath, "err", retErr)
+			}
+			return nil, err
+		}
+		leases = append(leases, lease)
+	}
+	return leases, nil
+}
+
+// TryAcquire tries to acquire n leases within timeout.
+// nil is returned if the leases are not acquired in time.
+func (s *Semaphore) TryAcquire(timeout time.Duration, n int) ([]*Lease, error) {
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var leases, err = s.Acquire(ctx, n)
+	if err == context.DeadlineExceeded {
+		return nil, nil
+	}
+	return leases, err
+}
+
+// Participants returns owner tokens of the lease nodes in order.
+func (s *Semaphore) Participants() ([]string, error) {
+	var children, _, err = s.client.GetChildren(s.leasePath)
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, child := range znodes.SortSequential(children, leaseNodeName) {
+		var data, _, err = s.client.Get(path.Join(s.leasePath, child))
+		if err == zk.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, string(data))
+	}
+	return result, nil
+}
+
+func (s *Semaphore) acquireOne(ctx context.Context) (*Lease, error) {
+	if err := s.mutex.Lock(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := s.mutex.Unlock(); err != nil {
+			s.client.Logger().Error("unlocking semaphore", "path", s.leasePath, "err", err)
+		}
+	}()
+
+	if err := znodes.EnsurePath(s.client, s.leasePath); err != nil {
+		return nil, err
+	}
+	var ourPath, err = s.client.CreateValue(path.Join(s.leasePath, leaseNodeName), []byte(s.owner),
+		enhanced.WithMode(enhanced.ModeEphemeral|enhanced.ModeSequential), enhanced.WithProtection())
+	if err != nil {
+		return nil, err
+	}
+	if err = s.waitForLease(ctx, ourPath); err != nil {
+		if delErr := znodes.Delete(s.client, ourPath); delErr != nil {
+			s.client.Logger().Error("deleting lease node", "path", ourPath, "err", delErr)
+		}
+		return nil, err
+	}
+	return &Lease{client: s.client, path: ourPath}, nil
+}
+
+// waitForLease blocks until ourPath is one of the first N lease nodes.
+func (s *Semaphore) waitForLease(ctx context.Context, ourPath string) error {
+	for {
+		var acquired, err = s.checkLease(ctx, ourPath)
+		if err != nil || acquired {
+			return err
+		}
+	}
+}
+
+// checkLease returns true if ourPath is granted, otherwise it blocks until
+// the lease nodes or the number of leases changes.
+func (s *Semaphore) checkLease(ctx context.Context, ourPath string) (bool, error) {
+	var watchCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	var changed = make(chan struct{}, 2)
+	var notify = func(zk.Event) {
+		changed <- struct{}{}
+	}
+
+	var maxLeases, err = s.watchMaxLeases(watchCtx, notify)
+	if err != nil {
+		return false, err
+	}
+	var result = make(chan enhanced.ChildrenResult, 1)
+	s.client.WatchChildrenCtx(watchCtx, s.leasePath, func(r enhanced.ChildrenResult) {
+		result <- r
+	}, notify)
+	var r = <-result
+	if r.Err != nil {
+		return false, r.Err
+	}
+
+	switch i := znodes.IndexOf(znodes.SortSequential(r.Children, leaseNodeName), path.Base(ourPath)); {
+	case i < 0:
+		return false, zk.ErrNoNode
+	case i < maxLeases:
+		return true, nil
+	}
+	select {
+	case <-changed:
+		return false, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// watchMaxLeases returns the number of leases, notify is called once it
+// changes in shared count mode.
+func (s *Semaphore) watchMaxLeases(ctx context.Context, notify func(zk.Event)) (int, error) {
+	if s.countPath == "" {
+		return s.maxLeases, nil
+	}
+	var result = make(chan enhanced.ExistResult, 1)
+	s.client.WatchExistCtx(ctx, s.countPath, func(r enhanced.ExistResult) {
+		result <- r
+	}, notify)
+	var r = <-result
+	if r.Err != nil || !r.Exist {
+		return 0, r.Err
+	}
+	var data, _, err = s.client.GetCtx(ctx, s.countPath)
+	if err == zk.ErrNoNode {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseCount(data)
+}
+
+// parseCount parses a count stored as a decimal integer.
+func parseCount(data []byte) (int, error) {
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
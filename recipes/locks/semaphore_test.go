@@ -0,0 +1,68 @@
+package locks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestParseCount(t *testing.T) {
+	var n, err = parseCount([]byte(" 3\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	_, err = parseCount([]byte("three"))
+	assert.Error(t, err)
+}
+
+func TestSemaphore(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var a = NewSemaphore(env.NewClientTimeout(time.Second*2), "/semaphore", 2)
+		var b = NewSemaphore(env.NewClientTimeout(time.Second*2), "/semaphore", 2)
+
+		leases, err := a.Acquire(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Len(t, leases, 2)
+
+		more, err := b.TryAcquire(time.Millisecond*100, 1)
+		assert.NoError(t, err)
+		assert.Nil(t, more)
+
+		assert.NoError(t, leases[0].Return())
+		more, err = b.TryAcquire(time.Second, 1)
+		assert.NoError(t, err)
+		assert.Len(t, more, 1)
+
+		participants, err := a.Participants()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{a.Owner(), b.Owner()}, participants)
+		assert.NoError(t, ReturnAll(append(leases[1:], more...)))
+	})
+}
+
+func TestSharedSemaphore(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		_, err := client.CreateValue("/count", []byte("1"))
+		assert.NoError(t, err)
+		var s = NewSharedSemaphore(client, "/semaphore", "/count")
+
+		leases, err := s.Acquire(context.Background(), 1)
+		assert.NoError(t, err)
+		more, err := s.TryAcquire(time.Millisecond*100, 1)
+		assert.NoError(t, err)
+		assert.Nil(t, more)
+
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			client.Set("/count", []byte("2"), -1)
+		}()
+		more, err = s.TryAcquire(time.Second*5, 1)
+		assert.NoError(t, err)
+		assert.Len(t, more, 1)
+		assert.NoError(t, ReturnAll(append(leases, more...)))
+	})
+}
@@ -0,0 +1,26 @@
+package nodes
+
+// Mode represents how the node of a PersistentEphemeral is created.
+type Mode int
+
+const (
+	// ModeEphemeral creates an ephemeral node.
+	ModeEphemeral Mode = iota
+	// ModeEphemeralSequential creates an ephemeral sequential node.
+	ModeEphemeralSequential
+	// ModeProtectedEphemeral creates an ephemeral node whose name is prefixed
+	// with a GUID, so it can be found again if the creation is interrupted
+	// by a connection loss.
+	ModeProtectedEphemeral
+	// ModeProtectedEphemeralSequential is ModeProtectedEphemeral with a
+	// sequential node.
+	ModeProtectedEphemeralSequential
+)
+
+func (m Mode) isSequential() bool {
+	return m == ModeEphemeralSequential || m == ModeProtectedEphemeralSequential
+}
+
+func (m Mode) isProtected() bool {
+	return m == ModeProtectedEphemeral || m == ModeProtectedEphemeralSequential
+}
@@ -0,0 +1,283 @@
+// Package nodes contains recipes of znodes kept by the client.
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/abool"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// retryInterval is the time to wait before trying again after a failure.
+const retryInterval = time.Second
+
+// PersistentEphemeral is an ephemeral node kept alive as long as it's started.
+//
+// The node is created again once it's deleted, e.g. after the session
+// expired, and its data is set back once it's changed by others.
+//
+// NOTE: A sequential node gets a new path every time it's created, Path
+// should be called for the current one.
+type PersistentEphemeral struct {
+	client        *enhanced.Client
+	path          string
+	mode          Mode
+	guid          string
+	logger        enhanced.Logger
+	stateListener *enhanced.StateChangeListener
+	started       *abool.AtomicBool
+	ctx           context.Context
+	cancel        context.CancelFunc
+	done          chan struct{}
+	// created is closed once the node is created for the first time.
+	created     chan struct{}
+	createdOnce sync.Once
+	// kick wakes up the loop to check the node.
+	kick chan struct{}
+
+	lock    sync.Mutex
+	data    []byte
+	ourPath string
+}
+
+// NewPersistentEphemeral creates a PersistentEphemeral at p with data.
+func NewPersistentEphemeral(client *enhanced.Client, p string, mode Mode, data []byte) *PersistentEphemeral {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var n = &PersistentEphemeral{
+		client:  client,
+		path:    path.Join("/", p),
+		mode:    mode,
+		guid:    znodes.NewToken(),
+		logger:  enhanced.NopLogger,
+		started: abool.New(),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		created: make(chan struct{}),
+		kick:    make(chan struct{}, 1),
+		data:    data,
+	}
+	if client != nil {
+		n.logger = client.Logger()
+	}
+	n.stateListener = enhanced.NewStateChangeListener(n.handleStateChange)
+	return n
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (n *PersistentEphemeral) SetLogger(logger enhanced.Logger) *PersistentEphemeral {
+	n.logger = logger
+	return n
+}
+
+// Start starts creating and keeping the node in the background.
+func (n *PersistentEphemeral) Start() error {
+	if !n.started.SetToIf(false, true) {
+		return ErrAlreadyStarted
+	}
+	n.client.AddStateChangeListener(n.stateListener)
+	go n.run()
+	return nil
+}
+
+// Close stops keeping the node and deletes it.
+func (n *PersistentEphemeral) Close() error {
+	if !n.started.SetToIf(true, false) {
+		return ErrNotStarted
+	}
+	n.client.DelStateChangeListener(n.stateListener)
+	n.cancel()
+	<-n.done
+
+	var ourPath = n.swapOurPath("")
+	if ourPath == "" {
+		return nil
+	}
+	var err = n.client.Delete(ourPath, -1)
+	if err == zk.ErrNoNode {
+		err = nil
+	}
+	return err
+}
+
+// WaitForInitialCreate blocks until the node is created for the first time
+// or ctx is done. The error of ctx is returned if ctx is done first.
+func (n *PersistentEphemeral) WaitForInitialCreate(ctx context.Context) error {
+	select {
+	case <-n.created:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Path returns the path of the node, "" is returned if it's not created.
+func (n *PersistentEphemeral) Path() string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.ourPath
+}
+
+// Data returns the data of the node.
+func (n *PersistentEphemeral) Data() []byte {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.data
+}
+
+// SetData sets the data of the node, it's written right away if the node
+// exists, otherwise the node is created with it later.
+func (n *PersistentEphemeral) SetData(data []byte) error {
+	n.lock.Lock()
+	n.data = data
+	var ourPath = n.ourPath
+	n.lock.Unlock()
+
+	var err error
+	if ourPath != "" {
+		_, err = n.client.Set(ourPath, data, -1)
+		if err == zk.ErrNoNode {
+			err = nil
+		}
+	}
+	n.wake()
+	return err
+}
+
+func (n *PersistentEphemeral) handleStateChange(s enhanced.ConnState) {
+	if s == enhanced.ConnStateReconnected {
+		// The node is gone if the session expired.
+		n.wake()
+	}
+}
+
+func (n *PersistentEphemeral) wake() {
+	select {
+	case n.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (n *PersistentEphemeral) run() {
+	defer close(n.done)
+	for n.ctx.Err() == nil {
+		if err := n.runOnce(); err != nil && n.ctx.Err() == nil {
+			n.logger.Warn("keeping persistent ephemeral node", "path", n.path, "err", err)
+			select {
+			case <-time.After(retryInterval):
+			case <-n.kick:
+			case <-n.ctx.Done():
+			}
+		}
+	}
+}
+
+// runOnce creates the node if necessary, sets its data back if it's changed,
+// then blocks until it's changed.
+func (n *PersistentEphemeral) runOnce() error {
+	var ourPath, err = n.ensure()
+	if err != nil || ourPath == "" {
+		return err
+	}
+
+	var ctx, cancel = context.WithCancel(n.ctx)
+	defer cancel()
+	var result = make(chan enhanced.DataResult, 1)
+	var changed = make(chan struct{})
+	n.client.WatchDataCtx(ctx, ourPath, func(r enhanced.DataResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	if r.Err == zk.ErrNoNode {
+		n.swapOurPath("")
+		return nil
+	}
+	if r.Err != nil {
+		return r.Err
+	}
+	var data = n.Data()
+	if !bytes.Equal(r.Data, data) {
+		_, err = n.client.Set(ourPath, data, r.Stat.Version)
+		if err == zk.ErrBadVersion || err == zk.ErrNoNode {
+			err = nil
+		}
+		return err
+	}
+	n.createdOnce.Do(func() { close(n.created) })
+
+	select {
+	case <-changed:
+	case <-n.kick:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// ensure returns the path of our node, which is created if necessary.
+// "" is returned once the node owned by another session is deleted.
+func (n *PersistentEphemeral) ensure() (string, error) {
+	n.lock.Lock()
+	var ourPath, data = n.ourPath, n.data
+	n.lock.Unlock()
+	if ourPath != "" {
+		return ourPath, nil
+	}
+
+	var dir, name = path.Dir(n.path), path.Base(n.path)
+	if dir != "/" {
+		if err := znodes.EnsurePath(n.client, dir); err != nil {
+			return "", err
+		}
+	}
+	var mode = enhanced.ModeEphemeral
+	if n.mode.isSequential() {
+		mode |= enhanced.ModeSequential
+	}
+	var p = n.path
+	if n.mode.isProtected() {
+		var found, err = n.client.FindProtected(dir, n.guid)
+		if err != nil {
+			return "", err
+		}
+		if found != "" {
+			n.swapOurPath(found)
+			return found, nil
+		}
+		p = path.Join(dir, enhanced.ProtectedName(n.guid, name))
+	}
+
+	var created, err = n.client.CreateValue(p, data, enhanced.WithMode(mode))
+	if err == zk.ErrNodeExists && !n.mode.isSequential() {
+		var exist, stat, err = n.client.Exist(p)
+		if err != nil || !exist {
+			return "", err
+		}
+		if stat.EphemeralOwner != n.client.Conn().SessionID() {
+			// Owned by others, or left by an expired session of ours. It's
+			// neither overwritten nor deleted, but watched until deleted.
+			return "", znodes.WaitForDeletion(n.ctx, n.client, p)
+		}
+		created = p
+	} else if err != nil {
+		return "", err
+	}
+	n.swapOurPath(created)
+	return created, nil
+}
+
+func (n *PersistentEphemeral) swapOurPath(p string) string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	var old = n.ourPath
+	n.ourPath = p
+	return old
+}
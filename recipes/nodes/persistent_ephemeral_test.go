@@ -0,0 +1,125 @@
+package nodes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 50; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond * 100)
+	}
+	t.Fatal("Waiting for condition timed out")
+}
+
+func TestPersistentEphemeral(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		var node = NewPersistentEphemeral(client, "/nodes/node", ModeEphemeral, []byte("a"))
+		assert.NoError(t, node.Start())
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		assert.NoError(t, node.WaitForInitialCreate(ctx))
+		assert.Equal(t, "/nodes/node", node.Path())
+
+		// Deleted by others.
+		assert.NoError(t, client.Delete("/nodes/node", -1))
+		waitFor(t, func() bool {
+			var exist, _, _ = client.Exist("/nodes/node")
+			return exist
+		})
+
+		// Changed by others.
+		_, err := client.Set("/nodes/node", []byte("b"), -1)
+		assert.NoError(t, err)
+		waitFor(t, func() bool {
+			var data, _, _ = client.Get("/nodes/node")
+			return string(data) == "a"
+		})
+
+		assert.NoError(t, node.SetData([]byte("c")))
+		data, _, err := client.Get("/nodes/node")
+		assert.NoError(t, err)
+		assert.Equal(t, "c", string(data))
+
+		assert.NoError(t, node.Close())
+		exist, _, err := client.Exist("/nodes/node")
+		assert.NoError(t, err)
+		assert.False(t, exist)
+		assert.Equal(t, ErrNotStarted, node.Close())
+	})
+}
+
+func TestProtectedSequential(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var client = env.NewClientTimeout(time.Second * 2)
+		var node = NewPersistentEphemeral(client, "/nodes/node-", ModeProtectedEphemeralSequential, nil)
+		assert.NoError(t, node.Start())
+		defer node.Close()
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		assert.NoError(t, node.WaitForInitialCreate(ctx))
+
+		var first = node.Path()
+		assert.True(t, strings.HasPrefix(first, "/nodes/"+enhanced.ProtectedName(node.guid, "node-")))
+		assert.NoError(t, client.Delete(first, -1))
+		waitFor(t, func() bool {
+			var p = node.Path()
+			return p != "" && p != first
+		})
+	})
+}
+
+func TestPersistentEphemeralOwnedByOthers(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var other = env.NewClientTimeout(time.Second * 2)
+		_, err := other.CreateValueWithParents("/nodes/node", []byte("other"),
+			enhanced.WithMode(enhanced.ModeEphemeral))
+		assert.NoError(t, err)
+
+		var client = env.NewClientTimeout(time.Second * 2)
+		var node = NewPersistentEphemeral(client, "/nodes/node", ModeEphemeral, []byte("a"))
+		assert.NoError(t, node.Start())
+		var ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*500)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, node.WaitForInitialCreate(ctx))
+		data, _, err := client.Get("/nodes/node")
+		assert.NoError(t, err)
+		assert.Equal(t, "other", string(data))
+
+		// Created once the node of others is gone.
+		other.Close()
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		assert.NoError(t, node.WaitForInitialCreate(ctx))
+		data, stat, err := client.Get("/nodes/node")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+		assert.Equal(t, client.Conn().SessionID(), stat.EphemeralOwner)
+		assert.NoError(t, node.Close())
+	})
+}
+
+func TestPersistentEphemeralCloseKeepsOthers(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var other = env.NewClientTimeout(time.Second * 2)
+		_, err := other.CreateValueWithParents("/nodes/node", []byte("other"),
+			enhanced.WithMode(enhanced.ModeEphemeral))
+		assert.NoError(t, err)
+
+		var node = NewPersistentEphemeral(env.NewClientTimeout(time.Second*2), "/nodes/node", ModeEphemeral, []byte("a"))
+		assert.NoError(t, node.Start())
+		time.Sleep(time.Millisecond * 500)
+		assert.NoError(t, node.Close())
+		env.AssertZNode("/nodes/node")
+	})
+}
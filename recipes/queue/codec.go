@@ -0,0 +1,57 @@
+package queue
+
+import "encoding/json"
+
+// Codec serializes items of a Queue.
+type Codec interface {
+	// Encode serializes v.
+	Encode(v interface{}) ([]byte, error)
+	// Decode deserializes data.
+	Decode(data []byte) (interface{}, error)
+}
+
+// BytesCodec passes []byte and string through as is, items are decoded as []byte.
+var BytesCodec Codec = bytesCodec{}
+
+type bytesCodec struct{}
+
+func (bytesCodec) Encode(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, ErrUnsupportedItem
+	}
+}
+
+func (bytesCodec) Decode(data []byte) (interface{}, error) {
+	return data, nil
+}
+
+// JSONCodec serializes items as JSON.
+type JSONCodec struct {
+	// New returns a pointer to decode into, items are decoded as generic
+	// JSON values if it's nil.
+	New func() interface{}
+}
+
+// Encode implements Codec.
+func (c JSONCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode implements Codec.
+func (c JSONCodec) Decode(data []byte) (interface{}, error) {
+	if c.New == nil {
+		var v interface{}
+		var err = json.Unmarshal(data, &v)
+		return v, err
+	}
+	var v = c.New()
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
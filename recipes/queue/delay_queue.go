@@ -0,0 +1,82 @@
+package queue
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tevino/zoo/enhanced"
+)
+
+// DelayQueue is a queue whose items are consumed only after their due time,
+// items are consumed in the order of their due time.
+//
+// The due time is encoded in the name of item nodes before the sequence,
+// consumers wait for the next item with a timer besides watching children.
+// Clocks of producers and consumers should be in sync.
+type DelayQueue struct {
+	keyedQueue
+}
+
+// NewDelayQueue creates a DelayQueue under p with BytesCodec.
+func NewDelayQueue(client *enhanced.Client, p string) *DelayQueue {
+	var q = NewQueue(client, p)
+	q.dueIn = dueIn
+	return &DelayQueue{keyedQueue: newKeyedQueue(q)}
+}
+
+// SetCodec sets the Codec of items.
+func (q *DelayQueue) SetCodec(c Codec) *DelayQueue {
+	q.queue.SetCodec(c)
+	return q
+}
+
+// SetLockPath makes consumers lock items under p instead of deleting them
+// once they are received, items are removed by Message.Ack then.
+func (q *DelayQueue) SetLockPath(p string) *DelayQueue {
+	q.queue.SetLockPath(p)
+	return q
+}
+
+// SetBufferSize sets the buffer size of the channel of messages.
+func (q *DelayQueue) SetBufferSize(size int) *DelayQueue {
+	q.queue.SetBufferSize(size)
+	return q
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (q *DelayQueue) SetLogger(logger enhanced.Logger) *DelayQueue {
+	q.queue.SetLogger(logger)
+	return q
+}
+
+// Put adds an item due at given time to the Queue.
+func (q *DelayQueue) Put(item interface{}, due time.Time) error {
+	return q.PutMulti(due, item)
+}
+
+// PutMulti adds items due at the same time to the Queue atomically in order.
+func (q *DelayQueue) PutMulti(due time.Time, items ...interface{}) error {
+	return q.putKeyed(dueKey(due), items)
+}
+
+// dueKey encodes due time in milliseconds, so keys sort in the same order.
+func dueKey(due time.Time) string {
+	var ms = due.UnixNano() / int64(time.Millisecond)
+	if ms < 0 {
+		ms = 0
+	}
+	return fmt.Sprintf("%016x", ms)
+}
+
+// dueIn returns the time until the item named name is due.
+func dueIn(name string, now time.Time) time.Duration {
+	var key, _ = parseKey(name)
+	var ms, err = strconv.ParseInt(key, 16, 64)
+	if err != nil {
+		// Malformed items are consumed right away.
+		return 0
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)).Sub(now)
+}
@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestDueIn(t *testing.T) {
+	var now = time.Now()
+	var name = keyedNodeName(dueKey(now.Add(time.Second))) + "0000000001"
+	var d = dueIn(name, now)
+	assert.True(t, d > time.Second-time.Millisecond && d <= time.Second, d)
+	assert.True(t, dueIn(name, now.Add(time.Second)) <= 0)
+	assert.Equal(t, time.Duration(0), dueIn("item-x-0000000001", now))
+}
+
+func TestDelayQueue(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var q = NewDelayQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		var now = time.Now()
+		assert.NoError(t, q.Put("later", now.Add(time.Millisecond*300)))
+		assert.NoError(t, q.Put("now", now))
+		assert.NoError(t, q.Start())
+
+		assert.Equal(t, []byte("now"), receive(t, q.queue).Value)
+		assert.Equal(t, []byte("later"), receive(t, q.queue).Value)
+		assert.False(t, time.Now().Before(now.Add(time.Millisecond*300)))
+		assert.NoError(t, q.Close())
+	})
+}
@@ -0,0 +1,12 @@
+package queue
+
+import "errors"
+
+var (
+	// ErrAlreadyStarted indicates Start is called more than once.
+	ErrAlreadyStarted = errors.New("already started")
+	// ErrNotStarted indicates the Queue is not started or closed already.
+	ErrNotStarted = errors.New("not started")
+	// ErrUnsupportedItem indicates an item can not be encoded by the Codec.
+	ErrUnsupportedItem = errors.New("unsupported item")
+)
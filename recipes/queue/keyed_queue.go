@@ -0,0 +1,73 @@
+package queue
+
+import (
+	"sort"
+	"strings"
+)
+
+// keyedQueue is the base of queues whose item nodes are named with a key
+// before the sequence, items are consumed in the order of their keys.
+type keyedQueue struct {
+	// queue is not embedded, since its Put and PutMulti add items which are
+	// not keyed.
+	queue *Queue
+}
+
+func newKeyedQueue(q *Queue) keyedQueue {
+	q.sortItems = sortByKey
+	return keyedQueue{queue: q}
+}
+
+// putKeyed adds items with key to the queue atomically in order.
+func (q keyedQueue) putKeyed(key string, items []interface{}) error {
+	return q.queue.putItems(keyedNodeName(key), items)
+}
+
+// Start starts consuming items, which are delivered over Messages.
+// A queue used only to put items does not need to be started.
+func (q keyedQueue) Start() error {
+	return q.queue.Start()
+}
+
+// Messages returns the channel of messages, which is closed once the queue
+// is closed. nil is returned if the queue is not started.
+func (q keyedQueue) Messages() <-chan *Message {
+	return q.queue.Messages()
+}
+
+// Close stops consuming items.
+// Items taken but not received from Messages are left in the queue.
+func (q keyedQueue) Close() error {
+	return q.queue.Close()
+}
+
+// keyedNodeName returns the name prefix of item nodes with key.
+func keyedNodeName(key string) string {
+	return itemNodeName + key + "-"
+}
+
+// parseKey returns the key of an item node name.
+func parseKey(name string) (string, bool) {
+	if !strings.HasPrefix(name, itemNodeName) {
+		return "", false
+	}
+	var rest = name[len(itemNodeName):]
+	var i = strings.IndexByte(rest, '-')
+	if i < 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// sortByKey sorts names of keyed item nodes, whose keys and sequences are
+// fixed width, names which are not keyed are dropped.
+func sortByKey(names []string) []string {
+	var sorted = make([]string, 0, len(names))
+	for _, name := range names {
+		if _, ok := parseKey(name); ok {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
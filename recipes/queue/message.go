@@ -0,0 +1,48 @@
+package queue
+
+import (
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+// Message is an item taken from a Queue.
+type Message struct {
+	// Value is the decoded item.
+	Value interface{}
+	// Err is the error of decoding the item.
+	Err error
+
+	client   *enhanced.Client
+	path     string
+	lockPath string
+	// claimPath is the claim node of the item unless it's locked.
+	claimPath string
+}
+
+// Path returns the path of the item node.
+func (m *Message) Path() string {
+	return m.path
+}
+
+// Ack removes the item from the Queue once it's processed.
+// It's a no-op unless the Queue consumes items with locks, in which case
+// the item is delivered again if it's never acknowledged.
+func (m *Message) Ack() error {
+	if m.lockPath == "" {
+		return nil
+	}
+	if err := m.client.Delete(m.path, -1); err != nil && err != zk.ErrNoNode {
+		return err
+	}
+	return znodes.Delete(m.client, m.lockPath)
+}
+
+// Release unlocks the item without removing it, so it's delivered again.
+// It's a no-op unless the Queue consumes items with locks.
+func (m *Message) Release() error {
+	if m.lockPath == "" {
+		return nil
+	}
+	return znodes.Delete(m.client, m.lockPath)
+}
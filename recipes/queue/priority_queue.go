@@ -0,0 +1,64 @@
+package queue
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tevino/zoo/enhanced"
+)
+
+// PriorityQueue is a queue whose items are consumed in the order of their
+// priorities, items with lower priority values are consumed first, and items
+// with the same priority are consumed in FIFO order.
+//
+// The priority is encoded in the name of item nodes before the sequence.
+type PriorityQueue struct {
+	keyedQueue
+}
+
+// NewPriorityQueue creates a PriorityQueue under p with BytesCodec.
+func NewPriorityQueue(client *enhanced.Client, p string) *PriorityQueue {
+	var q = NewQueue(client, p)
+	return &PriorityQueue{keyedQueue: newKeyedQueue(q)}
+}
+
+// SetCodec sets the Codec of items.
+func (q *PriorityQueue) SetCodec(c Codec) *PriorityQueue {
+	q.queue.SetCodec(c)
+	return q
+}
+
+// SetLockPath makes consumers lock items under p instead of deleting them
+// once they are received, items are removed by Message.Ack then.
+func (q *PriorityQueue) SetLockPath(p string) *PriorityQueue {
+	q.queue.SetLockPath(p)
+	return q
+}
+
+// SetBufferSize sets the buffer size of the channel of messages.
+func (q *PriorityQueue) SetBufferSize(size int) *PriorityQueue {
+	q.queue.SetBufferSize(size)
+	return q
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (q *PriorityQueue) SetLogger(logger enhanced.Logger) *PriorityQueue {
+	q.queue.SetLogger(logger)
+	return q
+}
+
+// Put adds an item with priority to the Queue.
+func (q *PriorityQueue) Put(item interface{}, priority int32) error {
+	return q.PutMulti(priority, item)
+}
+
+// PutMulti adds items with the same priority to the Queue atomically in order.
+func (q *PriorityQueue) PutMulti(priority int32, items ...interface{}) error {
+	return q.putKeyed(priorityKey(priority), items)
+}
+
+// priorityKey encodes priority so keys sort in the same order as priorities.
+func priorityKey(priority int32) string {
+	return fmt.Sprintf("%08x", uint32(int64(priority)-math.MinInt32))
+}
@@ -0,0 +1,37 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/test"
+)
+
+func TestPriorityKey(t *testing.T) {
+	var names = []string{
+		keyedNodeName(priorityKey(10)) + "0000000001",
+		keyedNodeName(priorityKey(-1)) + "0000000002",
+		keyedNodeName(priorityKey(0)) + "0000000003",
+		keyedNodeName(priorityKey(-1)) + "0000000004",
+		"lock",
+	}
+	assert.Equal(t, []string{names[1], names[3], names[2], names[0]}, sortByKey(names))
+
+	var key, ok = parseKey(names[2])
+	assert.True(t, ok)
+	assert.Equal(t, "80000000", key)
+}
+
+func TestPriorityQueue(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var q = NewPriorityQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, q.Put("low", 10))
+		assert.NoError(t, q.PutMulti(1, "high", "high2"))
+		assert.NoError(t, q.Start())
+		for _, want := range []string{"high", "high2", "low"} {
+			assert.Equal(t, []byte(want), receive(t, q.queue).Value)
+		}
+		assert.NoError(t, q.Close())
+	})
+}
@@ -0,0 +1,359 @@
+// Package queue contains recipes of distributed queues.
+package queue
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/tevino/abool"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/recipes/internal/znodes"
+)
+
+const (
+	// itemNodeName is the name prefix of item nodes.
+	itemNodeName = "item-"
+	// claimNodeName is the name of the ephemeral child of an item node which
+	// marks the item taken, unless items are locked under the lock path.
+	claimNodeName = "claim"
+	// retryInterval is the time to wait before listing items again after
+	// a failure, or when any item is locked by others, since releasing a lock
+	// does not change the children of the path.
+	retryInterval = time.Second
+)
+
+// Queue is a FIFO queue shared by processes.
+//
+// Every item is a persistent sequential node under the path, the data of
+// which is the item serialized by the Codec. Consumers are woken up by
+// watching the children of the path.
+//
+// An item is taken by exactly one consumer, either by claiming it with an
+// ephemeral child node and deleting it once it's received from Messages, or
+// by locking it with an ephemeral node under the lock path until Message.Ack
+// is called. Items stay in place until they are received, so the order is
+// kept if a consumer crashes or is closed. The latter also redelivers items
+// whose consumers crashed before acknowledging them.
+type Queue struct {
+	client     *enhanced.Client
+	path       string
+	codec      Codec
+	lockPath   string
+	bufferSize int
+	logger     enhanced.Logger
+	started    *abool.AtomicBool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	messages   chan *Message
+	done       chan struct{}
+	closeOnce  sync.Once
+	// sortItems sorts names of item nodes in the order of consuming.
+	sortItems func(names []string) []string
+	// dueIn returns the time until the item named name is due, items are
+	// always due if it's nil.
+	dueIn func(name string, now time.Time) time.Duration
+}
+
+// NewQueue creates a Queue under p with BytesCodec.
+func NewQueue(client *enhanced.Client, p string) *Queue {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var q = &Queue{
+		client:  client,
+		path:    path.Join("/", p),
+		codec:   BytesCodec,
+		logger:  enhanced.NopLogger,
+		started: abool.New(),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		sortItems: func(names []string) []string {
+			return znodes.SortSequential(names, itemNodeName)
+		},
+	}
+	if client != nil {
+		q.logger = client.Logger()
+	}
+	return q
+}
+
+// SetCodec sets the Codec of items.
+func (q *Queue) SetCodec(c Codec) *Queue {
+	q.codec = c
+	return q
+}
+
+// SetLockPath makes consumers lock items under p instead of deleting them
+// once they are received, items are removed by Message.Ack then.
+func (q *Queue) SetLockPath(p string) *Queue {
+	q.lockPath = path.Join("/", p)
+	return q
+}
+
+// SetBufferSize sets the buffer size of the channel of messages.
+func (q *Queue) SetBufferSize(size int) *Queue {
+	q.bufferSize = size
+	return q
+}
+
+// SetLogger sets the Logger, the Logger of the client is used by default.
+// It must be called before Start.
+func (q *Queue) SetLogger(logger enhanced.Logger) *Queue {
+	q.logger = logger
+	return q
+}
+
+// Put adds an item to the Queue.
+func (q *Queue) Put(item interface{}) error {
+	return q.PutMulti(item)
+}
+
+// PutMulti adds items to the Queue atomically in order.
+func (q *Queue) PutMulti(items ...interface{}) error {
+	return q.putItems(itemNodeName, items)
+}
+
+// putItems adds items named with prefix to the Queue atomically in order.
+func (q *Queue) putItems(prefix string, items []interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+	var values = make([][]byte, len(items))
+	for i, item := range items {
+		var data, err = q.codec.Encode(item)
+		if err != nil {
+			return err
+		}
+		values[i] = data
+	}
+	return q.putData(prefix, values...)
+}
+
+func (q *Queue) putData(prefix string, values ...[]byte) error {
+	var paths = make([]string, len(values))
+	for i := range paths {
+		paths[i] = path.Join(q.path, prefix)
+	}
+	if err := znodes.EnsurePath(q.client, q.path); err != nil {
+		return err
+	}
+	var _, err = znodes.CreateMulti(q.client, paths, values, enhanced.ModeSequential)
+	return err
+}
+
+// Start starts consuming items, which are delivered over Messages.
+// A Queue used only to put items does not need to be started.
+func (q *Queue) Start() error {
+	if !q.started.SetToIf(false, true) {
+		return ErrAlreadyStarted
+	}
+	if err := znodes.EnsurePath(q.client, q.path); err != nil {
+		return err
+	}
+	if q.lockPath != "" {
+		if err := znodes.EnsurePath(q.client, q.lockPath); err != nil {
+			return err
+		}
+	}
+	q.messages = make(chan *Message, q.bufferSize)
+	go q.consume()
+	return nil
+}
+
+// Messages returns the channel of messages, which is closed once the Queue
+// is closed. nil is returned if the Queue is not started.
+func (q *Queue) Messages() <-chan *Message {
+	return q.messages
+}
+
+// Close stops consuming items.
+// Items taken but not received from Messages are left in the Queue.
+func (q *Queue) Close() error {
+	if !q.started.IsSet() {
+		return ErrNotStarted
+	}
+	q.closeOnce.Do(func() {
+		q.cancel()
+		<-q.done
+	})
+	return nil
+}
+
+func (q *Queue) consume() {
+	defer close(q.done)
+	defer close(q.messages)
+	for q.ctx.Err() == nil {
+		q.consumeOnce()
+	}
+}
+
+// consumeOnce delivers all items due, then waits for changes unless any item
+// is taken. The wait ends early once the next item is due, or after
+// retryInterval if the listing failed or any item is locked by others.
+func (q *Queue) consumeOnce() {
+	var ctx, cancel = context.WithCancel(q.ctx)
+	defer cancel()
+	var taken, locked, nextDue, changed, err = q.takeAll(ctx)
+	var wait time.Duration
+	if err != nil {
+		if q.ctx.Err() == nil {
+			q.logger.Error("consuming queue", "path", q.path, "err", err)
+		}
+		changed = nil
+		wait = retryInterval
+	} else if taken {
+		// Check for more items right away.
+		return
+	} else if locked {
+		wait = retryInterval
+	}
+	if nextDue > 0 && (wait == 0 || nextDue < wait) {
+		wait = nextDue
+	}
+	var timeout <-chan time.Time
+	if wait > 0 {
+		var timer = time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-changed:
+	case <-timeout:
+	case <-q.ctx.Done():
+	}
+}
+
+// takeAll delivers all items due, changed is closed once items change.
+// The returning values indicate whether any item is taken, whether any item
+// is locked by others, and the time until the next item is due if any.
+func (q *Queue) takeAll(ctx context.Context) (bool, bool, time.Duration, <-chan struct{}, error) {
+	var result = make(chan enhanced.ChildrenResult, 1)
+	var changed = make(chan struct{})
+	q.client.WatchChildrenCtx(ctx, q.path, func(r enhanced.ChildrenResult) {
+		result <- r
+	}, func(zk.Event) {
+		close(changed)
+	})
+	var r = <-result
+	if r.Err != nil {
+		return false, false, 0, changed, r.Err
+	}
+
+	var taken, locked bool
+	for _, name := range q.sortItems(r.Children) {
+		if q.dueIn != nil {
+			if d := q.dueIn(name, time.Now()); d > 0 {
+				// Items are sorted by due time.
+				return taken, locked, d, changed, nil
+			}
+		}
+		var msg, lockedByOthers, err = q.take(name)
+		if err != nil {
+			return taken, locked, 0, changed, err
+		}
+		if msg == nil {
+			locked = locked || lockedByOthers
+			continue
+		}
+		taken = true
+		if !q.deliver(msg) {
+			return taken, locked, 0, changed, nil
+		}
+	}
+	return taken, locked, 0, changed, nil
+}
+
+// take takes the item named name, nil is returned if it's gone or taken by
+// others, the latter is indicated by the returning bool.
+func (q *Queue) take(name string) (*Message, bool, error) {
+	var msg = &Message{
+		client: q.client,
+		path:   path.Join(q.path, name),
+	}
+	var takenPath = path.Join(msg.path, claimNodeName)
+	if q.lockPath != "" {
+		msg.lockPath = path.Join(q.lockPath, name)
+		takenPath = msg.lockPath
+	} else {
+		msg.claimPath = takenPath
+	}
+	var _, err = q.client.Create(takenPath, enhanced.WithMode(enhanced.ModeEphemeral))
+	if err == zk.ErrNodeExists {
+		return nil, true, nil
+	}
+	if err == zk.ErrNoNode {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, _, err := q.client.GetCtx(q.ctx, msg.path)
+	if err != nil {
+		q.untake(msg)
+		if err == zk.ErrNoNode {
+			err = nil
+		}
+		return nil, false, err
+	}
+	msg.Value, msg.Err = q.codec.Decode(data)
+	return msg, false, nil
+}
+
+// untake makes the item taken available to consumers again.
+// A claim which fails to be deleted is gone along with the session.
+func (q *Queue) untake(msg *Message) {
+	var err error
+	if msg.claimPath != "" {
+		err = q.client.Delete(msg.claimPath, -1, enhanced.WithRetryPolicy(nil))
+		if err == zk.ErrNoNode {
+			err = nil
+		}
+	} else {
+		err = msg.Release()
+	}
+	if err != nil {
+		q.logger.Error("releasing queue item", "path", msg.path, "err", err)
+	}
+}
+
+// remove deletes the claimed item along with its claim.
+// It gives up once the Queue is closed, the item is delivered again once the
+// claim is gone along with the session then.
+func (q *Queue) remove(msg *Message) error {
+	for {
+		var _, err = q.client.Tx().Delete(msg.claimPath, -1).Delete(msg.path, -1).Commit()
+		if err == zk.ErrNoNode {
+			// Deleted before the connection was lost.
+			return nil
+		}
+		if !enhanced.IsRetryableErr(err) {
+			return err
+		}
+		select {
+		case <-time.After(retryInterval):
+		case <-q.ctx.Done():
+			return q.ctx.Err()
+		}
+	}
+}
+
+// deliver sends msg over Messages, a claimed item is removed once it's
+// received. msg is released if the Queue is closed in the meantime.
+// The returning value indicates whether msg is delivered.
+func (q *Queue) deliver(msg *Message) bool {
+	select {
+	case q.messages <- msg:
+	case <-q.ctx.Done():
+		q.untake(msg)
+		return false
+	}
+	if msg.claimPath != "" {
+		if err := q.remove(msg); err != nil {
+			q.logger.Error("removing queue item", "path", msg.path, "err", err)
+		}
+	}
+	return true
+}
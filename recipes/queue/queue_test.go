@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tevino/zoo/enhanced"
+	"github.com/tevino/zoo/test"
+)
+
+func TestCodec(t *testing.T) {
+	var data, err = BytesCodec.Encode("item")
+	assert.NoError(t, err)
+	v, err := BytesCodec.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("item"), v)
+	_, err = BytesCodec.Encode(1)
+	assert.Equal(t, ErrUnsupportedItem, err)
+
+	type item struct{ N int }
+	var codec = JSONCodec{New: func() interface{} { return &item{} }}
+	data, err = codec.Encode(item{1})
+	assert.NoError(t, err)
+	v, err = codec.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, &item{1}, v)
+}
+
+func receive(t *testing.T, q *Queue) *Message {
+	select {
+	case msg := <-q.Messages():
+		assert.NoError(t, msg.Err)
+		return msg
+	case <-time.After(time.Second * 5):
+		t.Fatal("Waiting for message timed out")
+	}
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var producer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		var consumer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, producer.Put("a"))
+		assert.NoError(t, producer.PutMulti("b", "c"))
+
+		assert.NoError(t, consumer.Start())
+		for _, want := range []string{"a", "b", "c"} {
+			assert.Equal(t, []byte(want), receive(t, consumer).Value)
+		}
+		assert.NoError(t, producer.Put("d"))
+		assert.Equal(t, []byte("d"), receive(t, consumer).Value)
+		assert.NoError(t, consumer.Close())
+
+		var children, _, err = env.Client().GetChildren("/queue")
+		assert.NoError(t, err)
+		assert.Empty(t, children)
+	})
+}
+
+func TestLockedQueue(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var q = NewQueue(env.NewClientTimeout(time.Second*2), "/queue").SetLockPath("/queue-lock")
+		assert.NoError(t, q.Put("a"))
+		assert.NoError(t, q.Start())
+
+		var msg = receive(t, q)
+		env.AssertZNode(msg.Path())
+		assert.NoError(t, msg.Release())
+		msg = receive(t, q)
+		assert.Equal(t, []byte("a"), msg.Value)
+		assert.NoError(t, msg.Ack())
+		env.AssertNoZNode(msg.Path(), path.Join("/queue-lock", path.Base(msg.Path())))
+		assert.NoError(t, q.Close())
+	})
+}
+
+func TestQueueCloseKeepsOrder(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var producer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, producer.PutMulti("a", "b"))
+		var children, _, err = env.Client().GetChildren("/queue")
+		assert.NoError(t, err)
+
+		// "a" is claimed but never received.
+		var consumer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, consumer.Start())
+		time.Sleep(time.Millisecond * 200)
+		assert.NoError(t, consumer.Close())
+
+		after, _, err := env.Client().GetChildren("/queue")
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, children, after)
+		for _, name := range after {
+			env.AssertNoZNode(path.Join("/queue", name, claimNodeName))
+		}
+
+		consumer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, consumer.Start())
+		assert.NoError(t, producer.Put("c"))
+		for _, want := range []string{"a", "b", "c"} {
+			assert.Equal(t, []byte(want), receive(t, consumer).Value)
+		}
+		assert.NoError(t, consumer.Close())
+	})
+}
+
+func TestQueueClaimedItemSkipped(t *testing.T) {
+	test.NewZkEnv(t).With(func(env *test.ZkEnv) {
+		var producer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, producer.PutMulti("a", "b"))
+		var children, _, err = env.Client().GetChildren("/queue")
+		assert.NoError(t, err)
+		var first = producer.sortItems(children)[0]
+
+		// Claimed by a consumer which crashes later.
+		var crashed = env.NewClientTimeout(time.Second * 2)
+		_, err = crashed.Create(path.Join("/queue", first, claimNodeName),
+			enhanced.WithMode(enhanced.ModeEphemeral))
+		assert.NoError(t, err)
+
+		var consumer = NewQueue(env.NewClientTimeout(time.Second*2), "/queue")
+		assert.NoError(t, consumer.Start())
+		assert.Equal(t, []byte("b"), receive(t, consumer).Value)
+		crashed.Close()
+		assert.Equal(t, []byte("a"), receive(t, consumer).Value)
+		assert.NoError(t, consumer.Close())
+	})
+}
@@ -30,6 +30,21 @@ func (c *ZkCluster) ConnectAll() (*enhanced.Client, error) {
 	return enhanced.Connect(servers, time.Second)
 }
 
+// ConnectProxied starts a client to the first server through a Proxy, which
+// is used to cut the client off.
+func (c *ZkCluster) ConnectProxied() (*enhanced.Client, *Proxy, error) {
+	var proxy, err = NewProxy(strings.Split(c.ConnectionString(), ",")[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := enhanced.Connect([]string{proxy.Addr()}, time.Second)
+	if err != nil {
+		proxy.Close()
+		return nil, nil, err
+	}
+	return client, proxy, nil
+}
+
 // ConnectionString returns connection string like: 127.0.0.1:21810,127.0.0.1:21811
 func (c *ZkCluster) ConnectionString() string {
 	if len(c.Servers) == 0 {
@@ -34,9 +34,9 @@ func (a *NodeAction) DoCreate(fullPath string, node ZNode) error {
 	var err error
 
 	if node.HasValue() {
-		err = a.client.CreateValueWithParents(fullPath, []byte(*node.Value))
+		_, err = a.client.CreateValueWithParents(fullPath, []byte(*node.Value))
 	} else {
-		err = a.client.CreateWithParents(fullPath)
+		_, err = a.client.CreateWithParents(fullPath)
 	}
 	return err
 }
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// SessionExpiryDowntime is the time to cut a client off to make its session
+// expire. Sessions of the managed cluster last at least 4s, which is twice the
+// default tick time of the server.
+const SessionExpiryDowntime = 8 * time.Second
+
+// Proxy is a TCP proxy to a ZooKeeper server, which cuts connections going
+// through it to simulate network failures.
+type Proxy struct {
+	target   string
+	listener net.Listener
+
+	lock   sync.Mutex
+	conns  map[net.Conn]struct{}
+	paused bool
+}
+
+// NewProxy starts a Proxy to target on a random local port.
+func NewProxy(target string) (*Proxy, error) {
+	var listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	var p = &Proxy{
+		target:   target,
+		listener: listener,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	go p.accept()
+	return p, nil
+}
+
+// Addr returns the address clients should connect to.
+func (p *Proxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+// Pause closes all connections, new connections are closed right away until
+// Resume is called.
+func (p *Proxy) Pause() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.paused = true
+	p.closeConns()
+}
+
+// Resume accepts connections again.
+func (p *Proxy) Resume() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.paused = false
+}
+
+// Interrupt pauses the Proxy for d.
+// Sessions of clients survive if d is shorter than the session timeout.
+func (p *Proxy) Interrupt(d time.Duration) {
+	p.Pause()
+	time.Sleep(d)
+	p.Resume()
+}
+
+// ExpireSession cuts clients off until their sessions expire.
+func (p *Proxy) ExpireSession() {
+	p.Interrupt(SessionExpiryDowntime)
+}
+
+// Close stops the Proxy and closes all connections.
+func (p *Proxy) Close() error {
+	var err = p.listener.Close()
+	p.lock.Lock()
+	p.closeConns()
+	p.lock.Unlock()
+	return err
+}
+
+func (p *Proxy) closeConns() {
+	for conn := range p.conns {
+		conn.Close()
+		delete(p.conns, conn)
+	}
+}
+
+func (p *Proxy) accept() {
+	for {
+		var conn, err = p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go p.serve(conn)
+	}
+}
+
+func (p *Proxy) serve(client net.Conn) {
+	p.lock.Lock()
+	var paused = p.paused
+	p.lock.Unlock()
+	if paused {
+		client.Close()
+		return
+	}
+	var server, err = net.Dial("tcp", p.target)
+	if err != nil {
+		client.Close()
+		return
+	}
+
+	p.lock.Lock()
+	if p.paused {
+		p.lock.Unlock()
+		client.Close()
+		server.Close()
+		return
+	}
+	p.conns[client] = struct{}{}
+	p.conns[server] = struct{}{}
+	p.lock.Unlock()
+
+	go p.pipe(server, client)
+	p.pipe(client, server)
+}
+
+// pipe copies from src to dst, both are closed once either side is done.
+func (p *Proxy) pipe(dst net.Conn, src net.Conn) {
+	io.Copy(dst, src)
+	p.lock.Lock()
+	delete(p.conns, dst)
+	delete(p.conns, src)
+	p.lock.Unlock()
+	dst.Close()
+	src.Close()
+}
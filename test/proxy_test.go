@@ -0,0 +1,77 @@
+package test
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startEchoServer(t *testing.T) net.Listener {
+	var listener, err = net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			var conn, err = listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var r = bufio.NewReader(conn)
+				for {
+					var line, err = r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					conn.Write([]byte(line))
+				}
+			}()
+		}
+	}()
+	return listener
+}
+
+func echo(conn net.Conn, msg string) (string, error) {
+	conn.SetDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+		return "", err
+	}
+	var line, err = bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return line[:len(line)-1], nil
+}
+
+func TestProxy(t *testing.T) {
+	var server = startEchoServer(t)
+	defer server.Close()
+	var proxy, err = NewProxy(server.Addr().String())
+	assert.NoError(t, err)
+	defer proxy.Close()
+
+	conn, err := net.Dial("tcp", proxy.Addr())
+	assert.NoError(t, err)
+	reply, err := echo(conn, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", reply)
+
+	proxy.Pause()
+	_, err = echo(conn, "b")
+	assert.Error(t, err)
+	paused, err := net.Dial("tcp", proxy.Addr())
+	assert.NoError(t, err)
+	_, err = echo(paused, "c")
+	assert.Error(t, err)
+
+	proxy.Resume()
+	conn, err = net.Dial("tcp", proxy.Addr())
+	assert.NoError(t, err)
+	reply, err = echo(conn, "d")
+	assert.NoError(t, err)
+	assert.Equal(t, "d", reply)
+	conn.Close()
+}
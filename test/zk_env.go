@@ -15,6 +15,7 @@ type ZkEnv struct {
 	assert    *assert.Assertions
 	zkCluster *ZkCluster
 	client    *enhanced.Client
+	proxies   []*Proxy
 	ZNodeAssertion
 	ClusterOperation
 }
@@ -46,6 +47,9 @@ func (z *ZkEnv) Start() *ZkEnv {
 func (z *ZkEnv) Stop() {
 	var err = z.zkCluster.Stop()
 	z.assert.NoError(err)
+	for _, p := range z.proxies {
+		p.Close()
+	}
 	if z.client != nil {
 		z.client.Close()
 	}
@@ -76,6 +80,17 @@ func (z *ZkEnv) NewClientTimeout(timeout time.Duration) *enhanced.Client {
 	return client
 }
 
+// NewProxiedClientTimeout is NewClientTimeout with the client connected through
+// a Proxy, which is used to cut the client off.
+func (z *ZkEnv) NewProxiedClientTimeout(timeout time.Duration) (*enhanced.Client, *Proxy) {
+	var client, proxy, err = z.zkCluster.ConnectProxied()
+	z.assert.NoError(err)
+	z.proxies = append(z.proxies, proxy)
+	var connected = client.BlockUntilConnected(timeout)
+	z.assert.True(connected)
+	return client, proxy
+}
+
 // ConnectionString returns the connection string of ZooKeeper cluster.
 func (z *ZkEnv) ConnectionString() string {
 	return z.zkCluster.ConnectionString()